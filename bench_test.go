@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func benchLogger(b *testing.B, lvl Level) Logger {
+	l, err := New(lvl, io.Discard, "", nil)
+	if err != nil {
+		b.Fatalf("Unexpected error: %+v\n", err)
+	}
+	return l
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	l := benchLogger(b, InfoLvl)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Debug("cache miss")
+	}
+}
+
+func BenchmarkDisabledf(b *testing.B) {
+	l := benchLogger(b, InfoLvl)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Debugf("cache miss for %s", "key")
+	}
+}
+
+func BenchmarkDisabledWithFields(b *testing.B) {
+	l := benchLogger(b, InfoLvl).AddFields(String("key", "value"), Int("attempt", 2), Duration("elapsed", time.Second))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Debug("cache miss")
+	}
+}
+
+func BenchmarkEnabled(b *testing.B) {
+	l := benchLogger(b, InfoLvl)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("listening")
+	}
+}
+
+func BenchmarkEnabledf(b *testing.B) {
+	l := benchLogger(b, InfoLvl)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Infof("listening on %s:%d", "localhost", 80)
+	}
+}
+
+func BenchmarkEnabledWithFields(b *testing.B) {
+	l := benchLogger(b, InfoLvl).AddFields(String("key", "value"), Int("attempt", 2), Duration("elapsed", time.Second), Bool("cached", false), Err(errors.New("timeout")))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("request failed")
+	}
+}
+
+func BenchmarkEnabledFlags(b *testing.B) {
+	l := benchLogger(b, InfoLvl).WithFlags(TimeUTC | TimeMicros | TimeZone | CallerModule)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("listening")
+	}
+}
+
+func BenchmarkEnabledParallel(b *testing.B) {
+	l := benchLogger(b, InfoLvl)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Infof("listening on %s:%d", "localhost", 80)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"sync"
+)
+
+// entryBuffer holds what writing a single line takes: the Entry handed to the Formatter, and the
+// buffer it's formatted into. They're pooled, so writing a line doesn't have to allocate either.
+type entryBuffer struct {
+	e   Entry
+	buf []byte
+}
+
+// maxPooledBuffer is the capacity above which buffers are dropped instead of being returned to the
+// pool, so one enormous line doesn't pin its memory for the life of the program.
+const maxPooledBuffer = 64 << 10
+
+var entryBuffers = sync.Pool{
+	New: func() interface{} {
+		return &entryBuffer{buf: make([]byte, 0, 512)}
+	},
+}
+
+func getEntryBuffer() *entryBuffer {
+	return entryBuffers.Get().(*entryBuffer)
+}
+
+func putEntryBuffer(b *entryBuffer) {
+	if cap(b.buf) > maxPooledBuffer {
+		return
+	}
+	b.e = Entry{}
+	b.buf = b.buf[:0]
+	entryBuffers.Put(b)
+}
@@ -0,0 +1,150 @@
+package logging
+
+import (
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// skipping is set once any helpers or packages have been registered, so finding the caller of a
+	// statement stays cheap for programs that don't use them.
+	skipping int32
+	helpers  sync.Map
+
+	skippedLock     sync.Mutex
+	skippedPackages atomic.Value
+)
+
+// Helper marks the function calling it as a logging helper, like testing.T's Helper method does for
+// test helpers. When deciding what file and line a statement was logged from, or where the stacktraces
+// sent to Sentry start, Loggers skip past helpers to the first function that isn't one. That makes it
+// possible to wrap a Logger without counting stack frames for WithCallDepth:
+//
+//	func (s *Server) logRequest(r *http.Request) {
+//		logging.Helper()
+//		s.log.Infof("%s %s", r.Method, r.URL)
+//	}
+//
+// Helper may be called from any number of goroutines, and calling it more than once is harmless.
+func Helper() {
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) < 1 {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	if _, ok := helpers.Load(frame.Function); ok {
+		return
+	}
+	helpers.Store(frame.Function, struct{}{})
+	atomic.StoreInt32(&skipping, 1)
+}
+
+// SkipPackages marks every function in the packages with the import paths in prefixes, and in the
+// packages below them, as a logging helper, as if they all called Helper. Only whole path elements
+// match: "github.com/acme/applog" covers "github.com/acme/applog/internal", but not
+// "github.com/acme/applogger". It's meant for libraries that wrap Loggers, which can
+// call it from an init function:
+//
+//	func init() {
+//		logging.SkipPackages("github.com/acme/applog")
+//	}
+func SkipPackages(prefixes ...string) {
+	skippedLock.Lock()
+	defer skippedLock.Unlock()
+	existing, _ := skippedPackages.Load().([]string)
+	updated := make([]string, 0, len(existing)+len(prefixes))
+	updated = append(updated, existing...)
+	for _, prefix := range prefixes {
+		if prefix != "" {
+			updated = append(updated, prefix)
+		}
+	}
+	skippedPackages.Store(updated)
+	atomic.StoreInt32(&skipping, 1)
+}
+
+// isHelper returns true if function, a fully-qualified function name as reported by the runtime, was
+// marked as a helper by Helper or SkipPackages.
+func isHelper(function string) bool {
+	if _, ok := helpers.Load(function); ok {
+		return true
+	}
+	prefixes, _ := skippedPackages.Load().([]string)
+	if len(prefixes) == 0 {
+		return false
+	}
+	pkg := packagePath(function)
+	for _, prefix := range prefixes {
+		if pkg == prefix || strings.HasPrefix(pkg, prefix) && pkg[len(prefix)] == '/' {
+			return true
+		}
+	}
+	return false
+}
+
+// callerSkip returns skip, the number of stack frames to ascend to find the caller of a statement, with
+// 0 identifying the caller of callerSkip as with runtime.Caller, adjusted to skip past any helpers.
+func callerSkip(skip int) int {
+	if atomic.LoadInt32(&skipping) == 0 {
+		return skip
+	}
+	var pcs [32]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for adjusted := skip; ; adjusted++ {
+		frame, more := frames.Next()
+		if !isHelper(frame.Function) {
+			return adjusted
+		}
+		if !more {
+			return skip
+		}
+	}
+}
+
+// callSite returns the program counter of a call site, identifying it cheaply. The argument skip is
+// the number of stack frames to ascend, with 0 identifying the caller of callSite, as with
+// runtime.Caller. Helpers are skipped. It returns 0 if the stack isn't that deep.
+func callSite(skip int) uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(callerSkip(skip+1)+1, pcs[:]) < 1 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// callerInfo is what Loggers need to know about a call site, in every form the caller Flags can ask
+// for. It's looked up once per program counter and cached, because symbolizing a program counter is
+// one of the more expensive parts of writing a line.
+type callerInfo struct {
+	file     string
+	line     int
+	short    string
+	module   string
+	function string
+}
+
+var callers sync.Map // map[uintptr]*callerInfo
+
+// callerAt returns the callerInfo for the call site identified by pc, as returned by callSite.
+func callerAt(pc uintptr) *callerInfo {
+	if c, ok := callers.Load(pc); ok {
+		return c.(*callerInfo)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	c := &callerInfo{file: frame.File, line: frame.Line, short: path.Base(frame.File)}
+	if c.file == "" {
+		c.file, c.short = "???", "???"
+	}
+	if frame.Function == "" {
+		c.module, c.function = c.short, c.short
+	} else {
+		c.module = moduleRelative(packagePath(frame.Function), c.short)
+		c.function = frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+	}
+	actual, _ := callers.LoadOrStore(pc, c)
+	return actual.(*callerInfo)
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func logFromHelper(l Logger, msg string) {
+	Helper()
+	l.Info(msg)
+}
+
+func logFromNestedHelper(l Logger, msg string) {
+	Helper()
+	logFromHelper(l, msg)
+}
+
+func logFromNonHelper(l Logger, msg string) {
+	l.Info(msg)
+}
+
+func TestHelper(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log = log.WithFlags(TimeNone | CallerShort)
+
+	_, _, line, _ := runtime.Caller(0)
+	logFromHelper(log, "helper")
+	logFromNestedHelper(log, "nested")
+	logFromNonHelper(log, "not a helper")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	expected := []string{
+		fmt.Sprintf("[INFO] caller_test.go:%d: helper", line+1),
+		fmt.Sprintf("[INFO] caller_test.go:%d: nested", line+2),
+		"[INFO] caller_test.go:23: not a helper",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %q instead\n", len(expected), buf.String())
+	}
+	for pos, exp := range expected {
+		if lines[pos] != exp {
+			t.Errorf("Expected line %d to be %q, got %q instead\n", pos, exp, lines[pos])
+		}
+	}
+}
+
+func TestHelperLevelOverrides(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log, err = log.WithLevelOverrides("caller_test.go=ERROR")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	logFromHelper(log, "helper")
+	if buf.Len() != 0 {
+		t.Errorf("Expected the override for the helper's caller to apply, got %q instead\n", buf.String())
+	}
+}
+
+func TestSkipPackages(t *testing.T) {
+	previous, _ := skippedPackages.Load().([]string)
+	wasSkipping := atomic.LoadInt32(&skipping)
+	t.Cleanup(func() {
+		skippedPackages.Store(previous)
+		atomic.StoreInt32(&skipping, wasSkipping)
+	})
+	SkipPackages("example.com/applog", "")
+	cases := map[string]bool{
+		"example.com/applog.Infof":                   true,
+		"example.com/applog/internal.(*Wrapper).Log": true,
+		"example.com/applogger.Infof":                false,
+		"example.com/applog2/internal.Log":           false,
+		"example.com/app.main":                       false,
+		"github.com/DramaFever/go-logging.Helper":    false,
+	}
+	for function, expected := range cases {
+		if got := isHelper(function); got != expected {
+			t.Errorf("Expected isHelper(%q) to be %v, got %v instead\n", function, expected, got)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// steppingClock returns start the first time it's asked, and one millisecond later every time after
+// that.
+func steppingClock(start time.Time) Clock {
+	next := start
+	return ClockFunc(func() time.Time {
+		now := next
+		next = next.Add(time.Millisecond)
+		return now
+	})
+}
+
+func TestClock(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithFlags(TimeUTC | TimeMillis | TimeZone | CallerShort).
+		WithClock(steppingClock(time.Date(2015, 7, 2, 13, 28, 42, 0, time.UTC)))
+
+	_, _, line, _ := runtime.Caller(0)
+	log.Infof("starting %s", "api")
+	log.AddFields(Int("attempt", 2)).Warn("retrying request")
+	log.Writer(ErrorLvl).Write([]byte("upstream closed connection\n"))
+	log.WithClock(nil).WithFlags(TimeNone | CallerNone).Debug("system clock")
+
+	expected := "2015-07-02T13:28:42.000Z [INFO] clock_test.go:" + strconv.Itoa(line+1) + ": starting api\n" +
+		"2015-07-02T13:28:42.001Z [WARN] clock_test.go:" + strconv.Itoa(line+2) + ": retrying request attempt=2\n" +
+		"2015-07-02T13:28:42.002Z [ERROR] clock_test.go:" + strconv.Itoa(line+3) + ": upstream closed connection\n" +
+		"[DEBUG] system clock\n"
+	if buf.String() != expected {
+		t.Errorf("Expected `%s`, got `%s` instead\n", expected, buf.String())
+	}
+}
+
+func TestClockCollapsedRepeats(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithFlags(TimeUnix | CallerNone).
+		WithClock(ClockFunc(func() time.Time { return time.Unix(1435843722, 0) })).
+		WithCollapsedRepeats(time.Hour)
+
+	for i := 0; i < 3; i++ {
+		log.Info("health check failed")
+	}
+	log.Info("health check passed")
+
+	expected := "1435843722 [INFO] health check failed\n" +
+		"1435843722 [INFO] last message repeated 2 times\n" +
+		"1435843722 [INFO] health check passed\n"
+	if buf.String() != expected {
+		t.Errorf("Expected `%s`, got `%s` instead\n", expected, buf.String())
+	}
+}
@@ -0,0 +1,154 @@
+package logging
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// collapser holds back runs of identical lines, the way syslogd does. All of its fields are guarded
+// by lock, which is the lock of the Loggers it was created for.
+type collapser struct {
+	timeout time.Duration
+	lock    *sync.Mutex
+
+	core      *core
+	sink      Sink
+	formatter Formatter
+	clock     Clock
+	flags     Flags
+	last      collapsedLine
+	repeats   int
+	timer     *time.Timer
+}
+
+// errHeld is returned by Logger.output for entries the collapser held back, so that they're not sent
+// to Sentry either.
+var errHeld = errors.New("logging: entry held back as a repeat")
+
+// collapsedLine identifies a line for spotting repeats. The program counter tells call sites apart
+// even when the caller isn't written, and context holds the entry's fields and tags, so the same
+// message about different things isn't mistaken for a repeat.
+type collapsedLine struct {
+	pc      uintptr
+	file    string
+	line    int
+	lvl     Level
+	msg     string
+	context string
+}
+
+// entryContext returns the fields and tags of e as a string, for telling entries with the same
+// message apart.
+func entryContext(e *Entry) string {
+	if len(e.Fields) == 0 && len(e.Tags) == 0 {
+		return ""
+	}
+	var buf []byte
+	for _, f := range e.Fields {
+		buf = append(buf, f.Key...)
+		buf = append(buf, '=')
+		buf = appendFieldValue(buf, f)
+		buf = append(buf, ' ')
+	}
+	keys := make([]string, 0, len(e.Tags))
+	for k := range e.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf = append(buf, '#')
+		buf = append(buf, k...)
+		buf = append(buf, '=')
+		buf = appendMaybeQuoted(buf, e.Tags[k])
+		buf = append(buf, ' ')
+	}
+	return string(buf)
+}
+
+// WithCollapsedRepeats returns a copy of l that collapses runs of identical messages logged from the
+// same line of code, with the same fields and tags. The first message of a run is written as usual, and the repeats are held back
+// until a different message is logged or timeout passes, at which point a single line saying
+// "last message repeated N times" is written in their place. After that, the next repeat starts a new
+// run. This keeps things like a flapping health check from filling the log with one line a second.
+// Repeats that are held back aren't sent to Sentry either, so a run of identical warnings or errors
+// is reported once.
+//
+// All Loggers derived from the returned Logger that write to the same output share the run, and any
+// held back repeats are accounted for when the Logger is closed. A timeout of 0 or less turns
+// collapsing off.
+func (l Logger) WithCollapsedRepeats(timeout time.Duration) Logger {
+	l.owns = nil
+	if timeout <= 0 {
+		l.collapser = nil
+		return l
+	}
+	lock := new(sync.Mutex)
+	if l.core != nil {
+		lock = l.core.lock
+	}
+	l.collapser = &collapser{timeout: timeout, lock: lock}
+	return l
+}
+
+// hold decides whether e, about to be written to the Sink of core, is a repeat of the previous entry,
+// and should be held back instead. When it isn't, hold writes the summary of the run it ends, if there
+// is one. Runs are told apart by core rather than by the Sink itself, since Sinks don't have to be
+// comparable. It must be called with c.lock held.
+func (c *collapser) hold(core *core, formatter Formatter, clock Clock, e *Entry) (bool, error) {
+	next := collapsedLine{pc: e.PC, file: e.File, line: e.Line, lvl: e.Level, msg: e.Message, context: entryContext(e)}
+	if core == c.core && next == c.last {
+		c.repeats++
+		if c.timer == nil {
+			c.timer = time.AfterFunc(c.timeout, c.expire)
+		}
+		return true, nil
+	}
+	err := c.writeSummary()
+	c.core = core
+	c.sink = core.sink
+	c.formatter = formatter
+	c.clock = clock
+	c.flags = e.Flags
+	c.last = next
+	return false, err
+}
+
+// writeSummary writes the line that stands in for the repeats held back so far, and ends the run. It
+// must be called with c.lock held.
+func (c *collapser) writeSummary() error {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if c.repeats == 0 {
+		return nil
+	}
+	e := Entry{
+		Time:    clockNow(c.clock),
+		Level:   c.last.lvl,
+		File:    c.last.file,
+		Line:    c.last.line,
+		Message: "last message repeated " + strconv.Itoa(c.repeats) + " times",
+		Flags:   c.flags,
+	}
+	buf := c.formatter.Format(nil, &e)
+	c.repeats = 0
+	c.last = collapsedLine{}
+	return c.sink.WriteEntry(&e, buf)
+}
+
+func (c *collapser) expire() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.writeSummary()
+}
+
+// flush writes the summary of the current run, if any repeats have been held back.
+func (c *collapser) flush() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.writeSummary()
+}
@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollapsedRepeats(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithCollapsedRepeats(time.Hour)
+	for i := 0; i < 5; i++ {
+		log.Info("health check failed")
+	}
+	log.Info("health check failed") // same message, different call site
+	log.Info("health check passed")
+	for i := 0; i < 3; i++ {
+		log.Infof("Test number %d", 1)
+	}
+	log.Close()
+
+	expected := []string{
+		"health check failed",
+		"last message repeated 4 times",
+		"health check failed",
+		"health check passed",
+		"Test number 1",
+		"last message repeated 2 times",
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got `%s`\n", len(expected), buf.String())
+	}
+	for pos, line := range lines {
+		if !strings.HasSuffix(line, expected[pos]) {
+			t.Errorf("Expected line %d to end with `%s`, got `%s`\n", pos, expected[pos], line)
+		}
+	}
+	if !strings.Contains(lines[1], "[INFO]") || !strings.Contains(lines[1], "collapse_test.go:18:") {
+		t.Errorf("Expected the summary to carry the repeated line's level and caller, got `%s`\n", lines[1])
+	}
+}
+
+func TestCollapsedRepeatsTimeout(t *testing.T) {
+	var buf lockedBuffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithCollapsedRepeats(20 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		log.Warn("flapping")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "repeated") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.HasSuffix(buf.String(), "last message repeated 2 times\n") {
+		t.Fatalf("Expected a summary once the timeout passed, got `%s`\n", buf.String())
+	}
+	log.Warn("flapping")
+	if strings.Count(buf.String(), "flapping") != 2 {
+		t.Errorf("Expected a repeat after the timeout to start a new run, got `%s`\n", buf.String())
+	}
+}
+
+func TestCollapsedRepeatsCallerNone(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithFlags(TimeNone | CallerNone).WithCollapsedRepeats(time.Hour)
+	log.Info("health check failed")
+	log.Info("health check failed") // same message, different call site
+	log.Close()
+	expected := "[INFO] health check failed\n[INFO] health check failed\n"
+	if buf.String() != expected {
+		t.Errorf("Expected call sites to be told apart without the caller, got `%s`\n", buf.String())
+	}
+}
+
+func TestCollapsedRepeatsReporting(t *testing.T) {
+	log, err := New(InfoLvl, &bytes.Buffer{}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	reporter := &recordingReporter{}
+	log = log.WithReporter(reporter).WithCollapsedRepeats(time.Hour)
+	for i := 0; i < 3; i++ {
+		log.Warn("flapping")
+	}
+	for i := 0; i < 3; i++ {
+		log.Errorf("backend %s down", "db")
+	}
+	log.Errorf("backend %s down", "db") // same message, different call site
+	log.Close()
+	if len(reporter.packets) != 3 {
+		t.Errorf("Expected held back repeats not to be reported, got %d packets instead\n", len(reporter.packets))
+	}
+}
+
+func TestCollapsedRepeatsFields(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	reporter := &recordingReporter{}
+	log = log.WithFlags(TimeNone | CallerNone).WithReporter(reporter).WithCollapsedRepeats(time.Hour)
+	for _, user := range []string{"alice", "bob", "bob", "carol"} {
+		log.AddFields(String("user", user)).Warn("login failed")
+	}
+	for _, region := range []string{"us", "eu"} {
+		log.AddTags(map[string]string{"region": region}).Warn("replica lagging")
+	}
+	log.Close()
+	expected := "[WARN] login failed user=alice\n" +
+		"[WARN] login failed user=bob\n" +
+		"[WARN] last message repeated 1 times\n" +
+		"[WARN] login failed user=carol\n" +
+		"[WARN] replica lagging\n" +
+		"[WARN] replica lagging\n"
+	if buf.String() != expected {
+		t.Errorf("Expected `%s`, got `%s` instead\n", expected, buf.String())
+	}
+	if len(reporter.packets) != 5 {
+		t.Errorf("Expected every entry but the repeat to be reported, got %d packets instead\n", len(reporter.packets))
+	}
+}
+
+type funcSink func(e *Entry, line []byte) error
+
+func (f funcSink) WriteEntry(e *Entry, line []byte) error {
+	return f(e, line)
+}
+
+func TestCollapsedRepeatsUncomparableSink(t *testing.T) {
+	log, err := New(InfoLvl, nil, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var messages []string
+	log = log.WithSink(funcSink(func(e *Entry, line []byte) error {
+		messages = append(messages, e.Message)
+		return nil
+	})).WithCollapsedRepeats(time.Hour)
+	for i := 0; i < 3; i++ {
+		log.Info("health check failed")
+	}
+	log.Close()
+	expected := []string{"health check failed", "last message repeated 2 times"}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %q, got %q instead\n", expected, messages)
+	}
+}
@@ -0,0 +1,267 @@
+package logging
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/getsentry/raven-go"
+)
+
+// Sink is where the entries a Logger writes end up. WriteEntry is handed both the Entry and the line
+// the Logger's Formatter made of it, so Sinks that write text can use the line as-is, and Sinks that
+// keep structured records can use the Entry. Loggers only call WriteEntry with their lock held, so
+// Sinks don't need any locking of their own, but they must not hold on to e or line once WriteEntry
+// returns.
+//
+// When a Logger using a Sink is closed, the Sink's Flush method is called if it has one. When the
+// Logger that introduced the Sink is closed, the Sink is closed too if it implements io.Closer.
+type Sink interface {
+	WriteEntry(e *Entry, line []byte) error
+}
+
+// WriterSink returns a Sink that writes formatted lines to w. Buffered writers are flushed using
+// their Flush method, or their Sync method, like *os.File has. w is never closed: whoever opened it
+// closes it, after closing the Logger. WithOutput uses WriterSink for the io.Writer it's given.
+func WriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+// closingWriterSink is WriterSink for the writers handed to New, which closes them along with the
+// Logger, as it always has. It leaves stdout and stderr alone, since the rest of the program is likely
+// still writing to them.
+func closingWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w, close: w != os.Stdout && w != os.Stderr}
+}
+
+type writerSink struct {
+	w     io.Writer
+	close bool
+}
+
+func (s *writerSink) WriteEntry(e *Entry, line []byte) error {
+	_, err := s.w.Write(line)
+	return err
+}
+
+// Flush pushes any data buffered by the writer to its destination. Writers like *bufio.Writer expose
+// this as Flush, and writers like *os.File expose it as Sync; anything else is assumed to be
+// unbuffered.
+func (s *writerSink) Flush() error {
+	switch w := s.w.(type) {
+	case interface {
+		Flush() error
+	}:
+		return w.Flush()
+	case interface {
+		Sync() error
+	}:
+		return w.Sync()
+	}
+	return nil
+}
+
+func (s *writerSink) Close() error {
+	if closer, ok := s.w.(io.Closer); ok && s.close {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Reporter sends the events Loggers report at WarnLvl and above to an error tracker. Report blocks
+// until the event has been delivered, or delivery has failed. Close is called once, when the Logger
+// that introduced the Reporter is closed, and should wait for any events still in flight.
+type Reporter interface {
+	Report(packet *raven.Packet, tags map[string]string) error
+	Close() error
+}
+
+// ErrReporterClosed is returned by the Reporters this package provides when they're asked to report
+// an event after they've been closed.
+var ErrReporterClosed = errors.New("logging: reporter is closed")
+
+// SentryReporter returns a Reporter that sends events to Sentry using client. New and WithSentry use
+// it for the clients they create.
+func SentryReporter(client *raven.Client) Reporter {
+	return &sentryReporter{client: client}
+}
+
+type sentryReporter struct {
+	client *raven.Client
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func (r *sentryReporter) Report(packet *raven.Packet, tags map[string]string) error {
+	r.mu.RLock()
+	if r.closed {
+		r.mu.RUnlock()
+		return ErrReporterClosed
+	}
+	_, ch := r.client.Capture(packet, tags)
+	r.mu.RUnlock()
+	return <-ch
+}
+
+func (r *sentryReporter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.client.Wait()
+	r.client.Close()
+	return nil
+}
+
+// core holds what a tree of Loggers shares: the Sink entries are written to, the Reporter events are
+// sent to, and the lock that serializes writes. The Logger New returns owns a core. So do the Loggers
+// WithSink, WithSentry, and WithReporter return, each a new core derived from the one they were
+// called on, which shares its lock and whichever of the Sink and Reporter it doesn't replace. Every
+// other Logger is a view of the core of the Logger it was derived from, except for the Loggers
+// WithOutput returns, which get a core of their own that nobody owns, since there's nothing for them
+// to close.
+//
+// A core counts references to itself: one for the Logger that owns it, and one for each core derived
+// from it that is still open. When the last one is released, it closes the Sink and Reporter it
+// introduced, and releases its parent in turn. That way, the Sink and Reporter a core shares with the
+// cores derived from it are closed exactly once, after all of them are done with them.
+type core struct {
+	sink     Sink
+	reporter Reporter
+	lock     *sync.Mutex
+	parent   *core
+
+	ownsSink     bool
+	ownsReporter bool
+
+	// occasions holds the state of the call sites that have used InfoEveryN, InfoFirstN, or
+	// InfoEvery with Loggers using this core.
+	occasions sync.Map // program counter of the call site -> *occasion
+
+	mu       sync.Mutex
+	refs     int
+	children map[*core]struct{}
+	closing  bool
+}
+
+func newCore(sink Sink, reporter Reporter) *core {
+	return &core{
+		sink:         sink,
+		reporter:     reporter,
+		lock:         new(sync.Mutex),
+		ownsSink:     sink != nil,
+		ownsReporter: reporter != nil,
+		refs:         1,
+	}
+}
+
+// derive returns a new core that shares c's lock, Sink, and Reporter, and holds a reference to c
+// until it's closed. Deriving from a nil core returns a new root.
+func (c *core) derive() *core {
+	if c == nil {
+		return newCore(nil, nil)
+	}
+	child := &core{sink: c.sink, reporter: c.reporter, lock: c.lock, refs: 1}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.refs == 0 {
+		return child
+	}
+	if c.children == nil {
+		c.children = map[*core]struct{}{}
+	}
+	c.children[child] = struct{}{}
+	c.refs++
+	child.parent = c
+	return child
+}
+
+// withSink returns a core derived from c that writes to sink instead.
+func (c *core) withSink(sink Sink) *core {
+	child := c.derive()
+	child.sink, child.ownsSink = sink, sink != nil
+	return child
+}
+
+// withOutput returns a core that writes to sink instead of c's Sink, and shares the rest of c. Unlike
+// the cores derive returns, it doesn't hold a reference to c, and c doesn't keep track of it, so it can
+// be dropped without being closed. It owns nothing, so it never closes anything either.
+func (c *core) withOutput(sink Sink) *core {
+	if c == nil {
+		return &core{sink: sink, lock: new(sync.Mutex), refs: 1}
+	}
+	return &core{sink: sink, reporter: c.reporter, lock: c.lock, refs: 1}
+}
+
+// withReporter returns a core derived from c that reports to reporter instead.
+func (c *core) withReporter(reporter Reporter) *core {
+	child := c.derive()
+	child.reporter, child.ownsReporter = reporter, reporter != nil
+	return child
+}
+
+// flush flushes c's Sink, if it's buffered.
+func (c *core) flush() {
+	if c == nil || c.sink == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if f, ok := c.sink.(interface {
+		Flush() error
+	}); ok {
+		f.Flush()
+	}
+}
+
+// close closes c on behalf of the Logger that owns it. The cores derived from c are closed first,
+// then the owner's reference is released. Closing a core more than once is harmless.
+func (c *core) close() {
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		return
+	}
+	c.closing = true
+	children := make([]*core, 0, len(c.children))
+	for child := range c.children {
+		children = append(children, child)
+	}
+	c.mu.Unlock()
+	for _, child := range children {
+		child.close()
+	}
+	c.release()
+}
+
+// release drops a reference to c, and shuts c down if it was the last one.
+func (c *core) release() {
+	c.mu.Lock()
+	c.refs--
+	last := c.refs == 0
+	c.mu.Unlock()
+	if !last {
+		return
+	}
+	if c.ownsReporter {
+		c.reporter.Close()
+	}
+	if c.ownsSink {
+		c.flush()
+		if closer, ok := c.sink.(io.Closer); ok {
+			c.lock.Lock()
+			closer.Close()
+			c.lock.Unlock()
+		}
+	}
+	if c.parent != nil {
+		c.parent.mu.Lock()
+		delete(c.parent.children, c)
+		c.parent.mu.Unlock()
+		c.parent.release()
+	}
+}
@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+var defaultLogger atomic.Value // Logger
+
+func init() {
+	l, _ := New(InfoLvl, os.Stderr, "", nil)
+	// Nobody owns the initial default, so closing it can't close stderr out from under the program.
+	l.owns = nil
+	defaultLogger.Store(l)
+}
+
+// Default returns the process-wide default Logger, which the package-level logging functions like
+// Infof write to, and which LogFromContext falls back on. Until SetDefault is called, it writes to
+// stderr, is set to InfoLvl, and has no Sentry configuration.
+func Default() Logger {
+	return defaultLogger.Load().(Logger)
+}
+
+// SetDefault makes l the process-wide default Logger. It's meant to be called once during program
+// initialization, after the Logger is configured:
+//
+//	log, err := logging.LogToStdout(logging.InfoLvl, dsn, nil)
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer log.Close()
+//	logging.SetDefault(log)
+//
+// It's safe to call SetDefault while other goroutines are logging.
+func SetDefault(l Logger) {
+	defaultLogger.Store(l)
+}
+
+// fromDefault returns the default Logger, adjusted to find the caller of the package-level function
+// that calls fromDefault.
+func fromDefault() Logger {
+	l := Default()
+	l.calldepth++
+	return l
+}
+
+// Tracef writes a log entry with the Level of TraceLvl to the default Logger. See Logger.Tracef.
+func Tracef(format string, msg ...interface{}) {
+	fromDefault().Tracef(format, msg...)
+}
+
+// Trace writes a log entry with the Level of TraceLvl to the default Logger. See Logger.Trace.
+func Trace(msg ...interface{}) {
+	fromDefault().Trace(msg...)
+}
+
+// Debugf writes a log entry with the Level of DebugLvl to the default Logger. See Logger.Debugf.
+func Debugf(format string, msg ...interface{}) {
+	fromDefault().Debugf(format, msg...)
+}
+
+// Debug writes a log entry with the Level of DebugLvl to the default Logger. See Logger.Debug.
+func Debug(msg ...interface{}) {
+	fromDefault().Debug(msg...)
+}
+
+// Infof writes a log entry with the Level of InfoLvl to the default Logger. See Logger.Infof.
+func Infof(format string, msg ...interface{}) {
+	fromDefault().Infof(format, msg...)
+}
+
+// Info writes a log entry with the Level of InfoLvl to the default Logger. See Logger.Info.
+func Info(msg ...interface{}) {
+	fromDefault().Info(msg...)
+}
+
+// Warnf writes a log entry with the Level of WarnLvl to the default Logger, and sends it to Sentry
+// if the default Logger is configured to. See Logger.Warnf.
+func Warnf(format string, msg ...interface{}) {
+	fromDefault().Warnf(format, msg...)
+}
+
+// Warn writes a log entry with the Level of WarnLvl to the default Logger, and sends it to Sentry if
+// the default Logger is configured to. See Logger.Warn.
+func Warn(msg ...interface{}) {
+	fromDefault().Warn(msg...)
+}
+
+// Errorf writes a log entry with the Level of ErrorLvl to the default Logger, and sends it to Sentry
+// if the default Logger is configured to. See Logger.Errorf.
+func Errorf(format string, msg ...interface{}) {
+	fromDefault().Errorf(format, msg...)
+}
+
+// Error writes a log entry with the Level of ErrorLvl to the default Logger, and sends it to Sentry
+// if the default Logger is configured to. See Logger.Error.
+func Error(msg ...interface{}) {
+	fromDefault().Error(msg...)
+}
+
+// Logf writes a log entry with the Level of lvl to the default Logger. See Logger.Logf.
+func Logf(lvl Level, format string, msg ...interface{}) {
+	fromDefault().Logf(lvl, format, msg...)
+}
+
+// Log writes a log entry with the Level of lvl to the default Logger. See Logger.Log.
+func Log(lvl Level, msg ...interface{}) {
+	fromDefault().Log(lvl, msg...)
+}
+
+// Panicf writes a log entry with the Level of PanicLvl to the default Logger, and then panics. See
+// Logger.Panicf.
+func Panicf(format string, msg ...interface{}) {
+	fromDefault().Panicf(format, msg...)
+}
+
+// Panic writes a log entry with the Level of PanicLvl to the default Logger, and then panics. See
+// Logger.Panic.
+func Panic(msg ...interface{}) {
+	fromDefault().Panic(msg...)
+}
+
+// Fatalf writes a log entry with the Level of FatalLvl to the default Logger, closes it, and exits
+// the program. See Logger.Fatalf.
+func Fatalf(format string, msg ...interface{}) {
+	fromDefault().Fatalf(format, msg...)
+}
+
+// Fatal writes a log entry with the Level of FatalLvl to the default Logger, closes it, and exits the
+// program. See Logger.Fatal.
+func Fatal(msg ...interface{}) {
+	fromDefault().Fatal(msg...)
+}
+
+// V returns a Verbose that logs to the default Logger at verbosity level n. See Logger.V.
+func V(n int) Verbose {
+	v := fromDefault().V(n)
+	// The Verbose's methods are called directly, not through a package-level function.
+	v.l.calldepth--
+	return v
+}
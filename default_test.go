@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/getsentry/raven-go"
+)
+
+func TestDefault(t *testing.T) {
+	if lvl := Default().GetLevel(); lvl != InfoLvl {
+		t.Errorf("Expected the default Logger to start at InfoLvl, got %s instead\n", lvl)
+	}
+	if LogFromContext(context.Background()).sink() != Default().sink() {
+		t.Error("Expected LogFromContext to fall back on the default Logger")
+	}
+
+	defer SetDefault(Default())
+	var buf bytes.Buffer
+	log, err := New(DebugLvl-1, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	reporter := &recordingReporter{}
+	SetDefault(log.WithFlags(TimeNone | CallerShort).WithReporter(reporter))
+
+	_, _, line, _ := runtime.Caller(0)
+	Infof("listening on %s", ":8080")
+	Debug("cache", "miss")
+	Trace("not written")
+	V(1).Infof("verbose %d", 1)
+	V(0).Info("verbose", 0)
+	Errorf("lost connection to %s", "db")
+
+	expected := []string{
+		fmt.Sprintf("[INFO] default_test.go:%d: listening on :8080", line+1),
+		fmt.Sprintf("[DEBUG] default_test.go:%d: cache miss", line+2),
+		fmt.Sprintf("[TRACE+3] default_test.go:%d: verbose 1", line+4),
+		fmt.Sprintf("[DEBUG] default_test.go:%d: verbose 0", line+5),
+		fmt.Sprintf("[ERROR] default_test.go:%d: lost connection to db", line+6),
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got `%s`\n", len(expected), buf.String())
+	}
+	for pos, exp := range expected {
+		if lines[pos] != exp {
+			t.Errorf("Expected line %d to be %q, got %q instead\n", pos, exp, lines[pos])
+		}
+	}
+
+	if len(reporter.packets) != 1 {
+		t.Fatalf("Expected 1 packet, got %d instead\n", len(reporter.packets))
+	}
+	var stack *raven.Stacktrace
+	for _, i := range reporter.packets[0].Interfaces {
+		if s, ok := i.(*raven.Stacktrace); ok {
+			stack = s
+		}
+	}
+	if stack == nil || len(stack.Frames) == 0 {
+		t.Fatal("Expected the event to have a stacktrace")
+	}
+	if frame := stack.Frames[len(stack.Frames)-1]; frame.Function != "TestDefault" || frame.Lineno != line+6 {
+		t.Errorf("Expected the stacktrace to start at TestDefault line %d, got %s line %d instead\n", line+6, frame.Function, frame.Lineno)
+	}
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"unicode/utf8"
+)
+
+// Escaping controls what a Logger does with control characters in messages. Messages often contain
+// input from outside the program, and without escaping, a newline in that input can forge a log line
+// that looks like it came from somewhere else, and an ANSI escape sequence can rewrite what a
+// terminal displays.
+type Escaping int
+
+const (
+	// EscapeNone writes messages verbatim. This is the default.
+	EscapeNone Escaping = iota
+	// EscapeSingleLine writes every message on a single line. Line breaks are written as \n and \r,
+	// and other control characters (including the ESC that starts ANSI escape sequences), Unicode line
+	// separators, and invalid UTF-8 are written as \x or \u escapes. Tabs are left alone.
+	EscapeSingleLine
+	// EscapeIndented escapes everything EscapeSingleLine does except \n, which is kept, but followed by
+	// a tab. Multi-line messages, like stack traces, stay readable, and every line that doesn't start
+	// with a tab is guaranteed to be the start of an entry.
+	EscapeIndented
+)
+
+// WithEscaping returns a copy of l that escapes control characters in messages as e specifies.
+func (l Logger) WithEscaping(e Escaping) Logger {
+	l.owns = nil
+	l.escaping = e
+	return l
+}
+
+const hexDigits = "0123456789abcdef"
+
+// needsEscaping returns true if appendEscaped would change s, other than by dropping a single
+// trailing newline.
+func needsEscaping(s string) bool {
+	if len(s) > 0 && s[len(s)-1] == '\n' {
+		s = s[:len(s)-1]
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !(c >= 0x20 && c < 0x7f || c == '\t') {
+			return true
+		}
+	}
+	return false
+}
+
+// appendEscaped appends s to buf, escaped as e specifies. A single trailing newline is dropped, so
+// the caller can end the entry itself. With EscapeNone, s is appended as is.
+func appendEscaped(buf []byte, s string, e Escaping) []byte {
+	if len(s) > 0 && s[len(s)-1] == '\n' {
+		s = s[:len(s)-1]
+	}
+	if e == EscapeNone {
+		return append(buf, s...)
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c < 0x7f || c == '\t' {
+			buf = append(buf, c)
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case c == '\n' && e == EscapeIndented:
+			buf = append(buf, '\n', '\t')
+		case c == '\n':
+			buf = append(buf, '\\', 'n')
+		case c == '\r':
+			buf = append(buf, '\\', 'r')
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, '\\', 'x', hexDigits[c>>4], hexDigits[c&0xf])
+		case r < 0x20 || r == 0x7f:
+			buf = append(buf, '\\', 'x', hexDigits[r>>4], hexDigits[r&0xf])
+		case r >= 0x80 && r < 0xa0 || r == '\u2028' || r == '\u2029':
+			buf = append(buf, '\\', 'u', hexDigits[r>>12&0xf], hexDigits[r>>8&0xf], hexDigits[r>>4&0xf], hexDigits[r&0xf])
+		default:
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return buf
+}
@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAppendEscaped(t *testing.T) {
+	type escapeTest struct {
+		in, singleLine, indented string
+	}
+	escapeTests := []escapeTest{
+		{in: "plain message\n", singleLine: "plain message", indented: "plain message"},
+		{in: "tab\tseparated", singleLine: "tab\tseparated", indented: "tab\tseparated"},
+		{
+			in:         "user bob\n2015-07-02T13:28:42 [INFO] forged line",
+			singleLine: `user bob\n2015-07-02T13:28:42 [INFO] forged line`,
+			indented:   "user bob\n\t2015-07-02T13:28:42 [INFO] forged line",
+		},
+		{in: "carriage\r return", singleLine: `carriage\r return`, indented: `carriage\r return`},
+		{in: "\x1b[31mred\x1b[0m", singleLine: `\x1b[31mred\x1b[0m`, indented: `\x1b[31mred\x1b[0m`},
+		{in: "bell\a del\x7f", singleLine: `bell\x07 del\x7f`, indented: `bell\x07 del\x7f`},
+		{in: "c1\u0085 sep\u2028", singleLine: `c1\u0085 sep\u2028`, indented: `c1\u0085 sep\u2028`},
+		{in: "bad \xff utf8", singleLine: `bad \xff utf8`, indented: `bad \xff utf8`},
+		{in: "unicode café ✓", singleLine: "unicode café ✓", indented: "unicode café ✓"},
+	}
+	for _, test := range escapeTests {
+		if out := string(appendEscaped(nil, test.in, EscapeSingleLine)); out != test.singleLine {
+			t.Errorf("Expected %q to escape to %q on a single line, got %q instead\n", test.in, test.singleLine, out)
+		}
+		if out := string(appendEscaped(nil, test.in, EscapeIndented)); out != test.indented {
+			t.Errorf("Expected %q to escape to %q indented, got %q instead\n", test.in, test.indented, out)
+		}
+	}
+}
+
+func TestLoggerEscaping(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithEscaping(EscapeSingleLine)
+	log.Infof("login failed for %s", "bob\n2015-07-02T13:28:42 [INFO] admin logged in")
+	log.Info("second line")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], `bob\n2015-07-02T13:28:42 [INFO] admin logged in`) {
+		t.Errorf("Expected two escaped lines, got `%s`\n", buf.String())
+	}
+}
+
+func TestConsoleEscaping(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithFormatter(&ConsoleFormatter{}).WithEscaping(EscapeSingleLine)
+	cause := fmt.Errorf("user bob\n13:28:42.107 INFO  admin logged in")
+	log.AddFields(Err(fmt.Errorf("login failed: %w", cause))).Info("rejected login")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected three lines, got `%s`\n", buf.String())
+	}
+	if expected := `    error: login failed: user bob\n13:28:42.107 INFO  admin logged in`; lines[1] != expected {
+		t.Errorf("Expected %q, got %q instead\n", expected, lines[1])
+	}
+	if expected := `      caused by: user bob\n13:28:42.107 INFO  admin logged in`; lines[2] != expected {
+		t.Errorf("Expected %q, got %q instead\n", expected, lines[2])
+	}
+}
+
+func TestNeedsEscaping(t *testing.T) {
+	cases := map[string]bool{
+		"":                      false,
+		"plain message":         false,
+		"plain message\n":       false,
+		"tab\tseparated":        false,
+		"two\nlines":            true,
+		"trailing newlines\n\n": true,
+		"\x1b[31mred":           true,
+		"del\x7f":               true,
+		"bad \xff utf8":         true,
+		"unicode café":          true,
+	}
+	for in, expected := range cases {
+		if got := needsEscaping(in); got != expected {
+			t.Errorf("Expected needsEscaping(%q) to be %v, got %v instead\n", in, expected, got)
+		}
+	}
+}
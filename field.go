@@ -0,0 +1,158 @@
+package logging
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+type fieldKind uint8
+
+const (
+	anyKind fieldKind = iota
+	stringKind
+	intKind
+	floatKind
+	boolKind
+	durationKind
+	errorKind
+)
+
+// Field is a key/value pair attached to log entries with AddFields. Fields are written after the
+// message, like key=value, and are sent to Sentry as extra data. Create them with the constructors
+// named after their types, like String and Int, or with Any.
+type Field struct {
+	Key  string
+	kind fieldKind
+	num  int64
+	str  string
+	val  interface{}
+}
+
+// String returns a Field with a string value.
+func String(key, val string) Field {
+	return Field{Key: key, kind: stringKind, str: val}
+}
+
+// Int returns a Field with an int value.
+func Int(key string, val int) Field {
+	return Field{Key: key, kind: intKind, num: int64(val)}
+}
+
+// Int64 returns a Field with an int64 value.
+func Int64(key string, val int64) Field {
+	return Field{Key: key, kind: intKind, num: val}
+}
+
+// Float64 returns a Field with a float64 value.
+func Float64(key string, val float64) Field {
+	return Field{Key: key, kind: floatKind, num: int64(math.Float64bits(val))}
+}
+
+// Bool returns a Field with a bool value.
+func Bool(key string, val bool) Field {
+	var num int64
+	if val {
+		num = 1
+	}
+	return Field{Key: key, kind: boolKind, num: num}
+}
+
+// Duration returns a Field with a time.Duration value.
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, kind: durationKind, num: int64(val)}
+}
+
+// Err returns a Field with the key "error" and err as its value. Formatters that know how to display
+// errors, like ConsoleFormatter, show the whole chain of errors that err wraps.
+func Err(err error) Field {
+	return Field{Key: "error", kind: errorKind, val: err}
+}
+
+// Any returns a Field with any value. Values of the types that have their own constructors get the
+// same treatment as if they were passed to them; anything else is formatted with fmt when written.
+func Any(key string, val interface{}) Field {
+	switch v := val.(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int64:
+		return Int64(key, v)
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case error:
+		return Field{Key: key, kind: errorKind, val: v}
+	}
+	return Field{Key: key, kind: anyKind, val: val}
+}
+
+// Value returns the value of f.
+func (f Field) Value() interface{} {
+	switch f.kind {
+	case stringKind:
+		return f.str
+	case intKind:
+		return f.num
+	case floatKind:
+		return math.Float64frombits(uint64(f.num))
+	case boolKind:
+		return f.num == 1
+	case durationKind:
+		return time.Duration(f.num)
+	}
+	return f.val
+}
+
+// AddFields copies the Logger, adds fields to the Logger, and returns the modified copy. Every entry
+// written by the copy ends with the fields, after any fields added to l before.
+func (l Logger) AddFields(fields ...Field) Logger {
+	newLogger := l.makeCopy()
+	newLogger.fields = append(newLogger.fields, fields...)
+	return newLogger
+}
+
+// appendFieldValue appends the value of f to buf as text, quoting it if it would be ambiguous
+// otherwise.
+func appendFieldValue(buf []byte, f Field) []byte {
+	switch f.kind {
+	case stringKind:
+		return appendMaybeQuoted(buf, f.str)
+	case intKind:
+		return strconv.AppendInt(buf, f.num, 10)
+	case floatKind:
+		return strconv.AppendFloat(buf, math.Float64frombits(uint64(f.num)), 'g', -1, 64)
+	case boolKind:
+		return strconv.AppendBool(buf, f.num == 1)
+	case durationKind:
+		return append(buf, time.Duration(f.num).String()...)
+	case errorKind:
+		if f.val == nil {
+			return append(buf, "<nil>"...)
+		}
+		return appendMaybeQuoted(buf, f.val.(error).Error())
+	}
+	return appendMaybeQuoted(buf, fmt.Sprint(f.val))
+}
+
+// appendMaybeQuoted appends s to buf, quoted if it is empty or contains anything but printable
+// characters other than spaces, quotes, and equals signs.
+func appendMaybeQuoted(buf []byte, s string) []byte {
+	if s == "" {
+		return append(buf, `""`...)
+	}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == ' ' || r == '=' || r == '"' || !strconv.IsPrint(r) || r == utf8.RuneError {
+			return strconv.AppendQuote(buf, s)
+		}
+		i += size
+	}
+	return append(buf, s...)
+}
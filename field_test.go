@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldValues(t *testing.T) {
+	type fieldTest struct {
+		field Field
+		value interface{}
+		text  string
+	}
+	err := errors.New("connection refused")
+	fieldTests := []fieldTest{
+		{field: String("host", "db-1"), value: "db-1", text: "db-1"},
+		{field: String("query", "SELECT 1"), value: "SELECT 1", text: `"SELECT 1"`},
+		{field: String("empty", ""), value: "", text: `""`},
+		{field: String("newline", "a\nb"), value: "a\nb", text: `"a\nb"`},
+		{field: Int("attempt", 2), value: int64(2), text: "2"},
+		{field: Int64("bytes", -1<<40), value: int64(-1 << 40), text: "-1099511627776"},
+		{field: Float64("ratio", 0.25), value: 0.25, text: "0.25"},
+		{field: Bool("cached", true), value: true, text: "true"},
+		{field: Duration("took", 1500*time.Millisecond), value: 1500 * time.Millisecond, text: "1.5s"},
+		{field: Err(err), value: err, text: `"connection refused"`},
+		{field: Any("port", 8080), value: int64(8080), text: "8080"},
+		{field: Any("ids", []int{1, 2}), value: []int{1, 2}, text: `"[1 2]"`},
+		{field: Any("key", Secret("hunter2")), value: Secret("hunter2"), text: "[REDACTED]"},
+	}
+	for _, test := range fieldTests {
+		if v := test.field.Value(); !reflect.DeepEqual(v, test.value) {
+			t.Errorf("Expected %s to have value %#v, got %#v instead\n", test.field.Key, test.value, v)
+		}
+		if text := string(appendFieldValue(nil, test.field)); text != test.text {
+			t.Errorf("Expected %s to be written as `%s`, got `%s` instead\n", test.field.Key, test.text, text)
+		}
+	}
+	if Err(err).Key != "error" {
+		t.Errorf("Expected Err to use the key error, got %s instead\n", Err(err).Key)
+	}
+}
+
+func TestAddFields(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	parent := log.AddFields(String("request", "abc"))
+	child := parent.AddFields(Int("attempt", 2), String("password", "hunter2"))
+	parent.AddFields(Bool("sibling", true))
+
+	child.Info("retrying")
+	if !strings.HasSuffix(buf.String(), "retrying request=abc attempt=2 password=hunter2\n") {
+		t.Errorf("Expected fields after the message, got `%s`\n", buf.String())
+	}
+	buf.Reset()
+	child.WithRedactor(DefaultRedactor()).Info("retrying")
+	if !strings.HasSuffix(buf.String(), "retrying request=abc attempt=2 password=[REDACTED]\n") {
+		t.Errorf("Expected sensitive fields to be redacted, got `%s`\n", buf.String())
+	}
+	buf.Reset()
+	parent.Info("done")
+	if !strings.HasSuffix(buf.String(), "done request=abc\n") {
+		t.Errorf("Expected fields added to copies not to leak into the parent, got `%s`\n", buf.String())
+	}
+}
@@ -0,0 +1,195 @@
+package logging
+
+import (
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Flags control what goes into the header TextFormatter writes before each message, in the same
+// spirit as the flags of the standard library's log package. The zero value writes the local time to
+// the second, which is what Loggers have always done:
+//
+//	2015-07-02T13:28:42 [WARN] /my/test/file.go:145: retrying request
+//
+// Flags are combined with |, so a Logger whose lines are shipped off to be merged with those of
+// other hosts might use
+//
+//	l = l.WithFlags(logging.TimeUTC | logging.TimeMillis | logging.TimeZone)
+//
+// to get 2015-07-02T17:28:42.107Z instead. When more than one precision flag is set, the most
+// precise wins.
+//
+// The caller flags, which shorten the absolute path of the file a line was logged from, work the same
+// way. When more than one is set, the one that leaves out the most wins, so CallerNone beats
+// CallerFunc, which beats CallerModule, which beats CallerShort. They keep build paths out of the
+// logs, and save a few bytes on every line:
+//
+//	l = l.WithFlags(logging.TimeUTC | logging.TimeZone | logging.CallerModule)
+type Flags uint32
+
+const (
+	// TimeUTC writes the time in UTC, rather than in the local time zone.
+	TimeUTC Flags = 1 << iota
+	// TimeMillis adds milliseconds to the time.
+	TimeMillis
+	// TimeMicros adds microseconds to the time.
+	TimeMicros
+	// TimeNanos adds nanoseconds to the time.
+	TimeNanos
+	// TimeZone adds the offset from UTC to the time, making it a full RFC 3339 timestamp. UTC is
+	// written as "Z".
+	TimeZone
+	// TimeUnix writes the number of seconds since the Unix epoch instead of a date and time. The
+	// precision flags add a fraction of a second to it.
+	TimeUnix
+	// TimeNone leaves the time out entirely. It's meant for programs run by journald or another
+	// supervisor that timestamps lines itself.
+	TimeNone
+	// CallerShort writes only the name of the file a line was logged from, like "store.go", instead
+	// of its absolute path.
+	CallerShort
+	// CallerModule writes the path of the file a line was logged from relative to the root of the
+	// module it belongs to, like "db/store.go". Files in packages that aren't part of any module the
+	// program was built with are identified by their package's import path instead.
+	CallerModule
+	// CallerFunc writes the package and function a line was logged from instead of its file, like
+	// "db.(*Store).Get:145". The package is named by the last element of its import path.
+	CallerFunc
+	// CallerNone leaves the caller out entirely, which also saves the cost of looking it up.
+	CallerNone
+)
+
+// WithFlags returns a copy of l that formats its output according to flags, replacing any flags set
+// before.
+func (l Logger) WithFlags(flags Flags) Logger {
+	l.owns = nil
+	l.flags = flags
+	return l
+}
+
+// GetFlags returns the Flags l was configured with.
+func (l Logger) GetFlags() Flags {
+	return l.flags
+}
+
+// appendTime appends t to buf, formatted according to flags. Like formatHeader, it avoids
+// time.Format, which is comparatively slow.
+func appendTime(buf *[]byte, t time.Time, flags Flags) {
+	if flags&TimeUTC != 0 {
+		t = t.UTC()
+	}
+	if flags&TimeUnix != 0 {
+		sec := t.Unix()
+		if sec < 0 {
+			*buf = append(*buf, '-')
+			sec = -sec
+		}
+		*buf = strconv.AppendInt(*buf, sec, 10)
+		appendFraction(buf, t, flags)
+		return
+	}
+	year, month, day := t.Date()
+	itoa(buf, year, 4)
+	*buf = append(*buf, '-')
+	itoa(buf, int(month), 2)
+	*buf = append(*buf, '-')
+	itoa(buf, day, 2)
+	*buf = append(*buf, 'T')
+	hour, minute, second := t.Clock()
+	itoa(buf, hour, 2)
+	*buf = append(*buf, ':')
+	itoa(buf, minute, 2)
+	*buf = append(*buf, ':')
+	itoa(buf, second, 2)
+	appendFraction(buf, t, flags)
+	if flags&TimeZone == 0 {
+		return
+	}
+	_, offset := t.Zone()
+	if offset == 0 {
+		*buf = append(*buf, 'Z')
+		return
+	}
+	if offset < 0 {
+		*buf = append(*buf, '-')
+		offset = -offset
+	} else {
+		*buf = append(*buf, '+')
+	}
+	itoa(buf, offset/3600, 2)
+	*buf = append(*buf, ':')
+	itoa(buf, offset%3600/60, 2)
+}
+
+// appendFraction appends the fraction of a second of t to buf, at the most precise precision set in
+// flags, if any.
+func appendFraction(buf *[]byte, t time.Time, flags Flags) {
+	switch {
+	case flags&TimeNanos != 0:
+		*buf = append(*buf, '.')
+		itoa(buf, t.Nanosecond(), 9)
+	case flags&TimeMicros != 0:
+		*buf = append(*buf, '.')
+		itoa(buf, t.Nanosecond()/int(time.Microsecond), 6)
+	case flags&TimeMillis != 0:
+		*buf = append(*buf, '.')
+		itoa(buf, t.Nanosecond()/int(time.Millisecond), 3)
+	}
+}
+
+// formatCaller returns c as the caller flags in flags say it should be written.
+func formatCaller(c *callerInfo, flags Flags) string {
+	switch {
+	case flags&CallerFunc != 0:
+		return c.function
+	case flags&CallerModule != 0:
+		return c.module
+	case flags&CallerShort != 0:
+		return c.short
+	}
+	return c.file
+}
+
+var (
+	buildOnce   sync.Once
+	mainPackage string
+	modules     []string
+)
+
+// moduleRelative returns the path of a file in the package pkg relative to the root of the module
+// that contains it, or pkg followed by file if no module the program was built with contains it.
+func moduleRelative(pkg, file string) string {
+	buildOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		mainPackage = info.Path
+		modules = append(modules, info.Main.Path)
+		for _, dep := range info.Deps {
+			modules = append(modules, dep.Path)
+		}
+	})
+	if pkg == "main" && mainPackage != "" {
+		pkg = mainPackage
+	}
+	var root string
+	for _, mod := range modules {
+		if mod == "" || len(mod) <= len(root) {
+			continue
+		}
+		if pkg == mod || strings.HasPrefix(pkg, mod+"/") {
+			root = mod
+		}
+	}
+	switch {
+	case root == "":
+		return pkg + "/" + file
+	case pkg == root:
+		return file
+	}
+	return pkg[len(root)+1:] + "/" + file
+}
@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCallerFlags(t *testing.T) {
+	cases := map[Flags]string{
+		CallerShort:                      "[INFO] flags_test.go:",
+		CallerModule:                     "[INFO] flags_test.go:",
+		CallerFunc:                       ".TestCallerFlags:",
+		CallerFunc | CallerShort:         ".TestCallerFlags:",
+		CallerNone:                       "[INFO] hello\n",
+		CallerNone | CallerFunc:          "[INFO] hello\n",
+		TimeNone | CallerNone:            "[INFO] hello\n",
+		TimeNone | CallerShort:           "[INFO] flags_test.go:",
+		TimeNone | CallerFunc:            ".TestCallerFlags:",
+		TimeNone | CallerModule:          "[INFO] flags_test.go:",
+		TimeUTC | TimeZone | CallerShort: "Z [INFO] flags_test.go:",
+	}
+	for flags, expected := range cases {
+		var buf bytes.Buffer
+		l, err := New(DebugLvl, &buf, "", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v\n", err)
+		}
+		l.WithFlags(flags).Info("hello")
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected output with flags %b to contain %q, got %q instead\n", flags, expected, buf.String())
+		}
+		if flags&TimeNone != 0 && flags&CallerFunc == 0 && !strings.HasPrefix(buf.String(), expected) {
+			t.Errorf("Expected output with flags %b to start with %q, got %q instead\n", flags, expected, buf.String())
+		}
+		if flags&CallerFunc != 0 && flags&CallerNone == 0 && strings.Contains(buf.String(), "/") {
+			t.Errorf("Expected output with flags %b to contain no paths, got %q instead\n", flags, buf.String())
+		}
+	}
+}
+
+func TestModuleRelative(t *testing.T) {
+	buildOnce.Do(func() {})
+	defer func(main string, mods []string) {
+		mainPackage, modules = main, mods
+	}(mainPackage, modules)
+	mainPackage = "github.com/acme/myapp/cmd/server"
+	modules = []string{"github.com/acme/myapp", "github.com/acme/myapp/tools", "github.com/acme/lib", ""}
+
+	cases := []struct {
+		pkg, file, expected string
+	}{
+		{"github.com/acme/myapp", "app.go", "app.go"},
+		{"github.com/acme/myapp/db", "store.go", "db/store.go"},
+		{"github.com/acme/myapp/tools/gen", "gen.go", "gen/gen.go"},
+		{"github.com/acme/lib/v2", "lib.go", "v2/lib.go"},
+		{"github.com/acme/library", "lib.go", "github.com/acme/library/lib.go"},
+		{"main", "main.go", "cmd/server/main.go"},
+		{"net/http", "server.go", "net/http/server.go"},
+	}
+	for _, c := range cases {
+		if got := moduleRelative(c.pkg, c.file); got != c.expected {
+			t.Errorf("Expected %s in %s to be %q, got %q instead\n", c.file, c.pkg, c.expected, got)
+		}
+	}
+}
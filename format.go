@@ -0,0 +1,254 @@
+package logging
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Entry is a single log statement, as handed to a Formatter. The Message has already been redacted
+// and escaped according to the Logger's settings, and has no trailing newline. File is the caller as
+// the Logger's caller Flags say to write it, and is empty if CallerNone is set. PC is the program
+// counter of the call site, or 0 if it isn't known. Flags are the Flags the Logger was configured
+// with; Formatters should honor those that make sense for them. Tags are the Sentry tags added to the
+// Logger, which the Formatters in this package don't write, but Sinks that keep records may.
+// Escaping is the Logger's Escaping, for Formatters that write other text from outside the program,
+// like error messages, on lines of their own.
+type Entry struct {
+	Time     time.Time
+	Level    Level
+	PC       uintptr
+	File     string
+	Line     int
+	Message  string
+	Fields   []Field
+	Flags    Flags
+	Escaping Escaping
+	Tags     map[string]string
+}
+
+// Formatter turns Entries into the bytes written to a Logger's output. Format appends the
+// formatted Entry, including its trailing newline, to buf and returns the extended buffer.
+// Formatters must be safe for concurrent use. Both buf and e are reused once Format returns, so
+// Formatters must not hold on to either; the strings inside e are safe to keep.
+type Formatter interface {
+	Format(buf []byte, e *Entry) []byte
+}
+
+// WithFormatter returns a copy of l that formats its output with f. A nil Formatter restores the
+// default, TextFormatter.
+func (l Logger) WithFormatter(f Formatter) Logger {
+	l.owns = nil
+	l.formatter = f
+	return l
+}
+
+func (l Logger) getFormatter() Formatter {
+	if l.formatter == nil {
+		return TextFormatter{}
+	}
+	return l.formatter
+}
+
+// TextFormatter is the default Formatter. It writes one line per Entry, made up of the time, the
+// Level, the file and line the Entry was logged from, the message, and the fields:
+//
+//	2015-07-02T13:28:42 [WARN] /my/test/file.go:145: retrying request attempt=2 backend=db-1
+type TextFormatter struct{}
+
+// Format implements Formatter.
+func (TextFormatter) Format(buf []byte, e *Entry) []byte {
+	formatHeader(&buf, e.Time, e.File, e.Line, e.Level, e.Flags)
+	buf = append(buf, e.Message...)
+	for _, f := range e.Fields {
+		buf = append(buf, ' ')
+		buf = append(buf, f.Key...)
+		buf = append(buf, '=')
+		buf = appendFieldValue(buf, f)
+	}
+	return append(buf, '\n')
+}
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiFaint   = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiCyan    = "\x1b[36m"
+	ansiBoldRed = "\x1b[1;31m"
+)
+
+const (
+	consoleCallerWidth  = 24
+	consoleMessageWidth = 40
+)
+
+// ConsoleFormatter is a Formatter meant for people reading logs in a terminal during development.
+// It writes the time of day to the millisecond, a fixed-width Level, and a shortened caller, so the
+// messages line up in a column, followed by the fields. Errors in fields are shown along with every
+// error they wrap, one per line. When Color is true, the Level is colored by severity and the field
+// names are dimmed. Of the time Flags, only TimeUTC and TimeNone apply; the time of day is always
+// shown to the millisecond.
+//
+//	13:28:42.107 WARN  db/store.go:145         retrying request                        attempt=2
+type ConsoleFormatter struct {
+	Color bool
+}
+
+// NewConsoleFormatter returns a ConsoleFormatter for out. Color is used if out is a terminal, unless
+// the NO_COLOR environment variable is set; setting FORCE_COLOR turns it on regardless.
+func NewConsoleFormatter(out io.Writer) *ConsoleFormatter {
+	return &ConsoleFormatter{Color: useColor(out)}
+}
+
+// AutoFormatter returns a colored ConsoleFormatter if out is a terminal (or the FORCE_COLOR
+// environment variable is set), and a TextFormatter otherwise. It's a good default for programs that
+// run both on a developer's machine and in production:
+//
+//	l = l.WithFormatter(logging.AutoFormatter(os.Stderr))
+func AutoFormatter(out io.Writer) Formatter {
+	if forceColor() || isTerminal(out) {
+		return NewConsoleFormatter(out)
+	}
+	return TextFormatter{}
+}
+
+// Format implements Formatter.
+func (c *ConsoleFormatter) Format(buf []byte, e *Entry) []byte {
+	if e.Flags&TimeNone == 0 {
+		now := e.Time
+		if e.Flags&TimeUTC != 0 {
+			now = now.UTC()
+		}
+		hour, minute, second := now.Clock()
+		itoa(&buf, hour, 2)
+		buf = append(buf, ':')
+		itoa(&buf, minute, 2)
+		buf = append(buf, ':')
+		itoa(&buf, second, 2)
+		buf = append(buf, '.')
+		itoa(&buf, now.Nanosecond()/int(time.Millisecond), 3)
+		buf = append(buf, ' ')
+	}
+
+	name := e.Level.String()
+	if c.Color {
+		buf = append(buf, levelColor(e.Level)...)
+	}
+	buf = append(buf, name...)
+	if c.Color {
+		buf = append(buf, ansiReset...)
+	}
+	buf = appendPadding(buf, len(name), 6)
+
+	if e.File != "" {
+		start := len(buf)
+		buf = append(buf, shortCaller(e.File)...)
+		buf = append(buf, ':')
+		itoa(&buf, e.Line, -1)
+		buf = appendPadding(buf, len(buf)-start, consoleCallerWidth)
+	}
+
+	buf = append(buf, e.Message...)
+	if len(e.Fields) > 0 {
+		buf = appendPadding(buf, len(e.Message), consoleMessageWidth)
+	}
+	for pos, f := range e.Fields {
+		if pos > 0 {
+			buf = append(buf, ' ')
+		}
+		if c.Color {
+			buf = append(buf, ansiFaint...)
+		}
+		buf = append(buf, f.Key...)
+		buf = append(buf, '=')
+		if c.Color {
+			buf = append(buf, ansiReset...)
+		}
+		buf = appendFieldValue(buf, f)
+	}
+	buf = append(buf, '\n')
+
+	for _, f := range e.Fields {
+		err, ok := f.val.(error)
+		if f.kind != errorKind || !ok || errors.Unwrap(err) == nil {
+			continue
+		}
+		buf = append(buf, "    "...)
+		buf = append(buf, f.Key...)
+		buf = append(buf, ": "...)
+		buf = appendEscaped(buf, err.Error(), e.Escaping)
+		buf = append(buf, '\n')
+		for err = errors.Unwrap(err); err != nil; err = errors.Unwrap(err) {
+			buf = append(buf, "      caused by: "...)
+			buf = appendEscaped(buf, err.Error(), e.Escaping)
+			buf = append(buf, '\n')
+		}
+	}
+	return buf
+}
+
+func levelColor(lvl Level) string {
+	switch {
+	case lvl < InfoLvl:
+		return ansiCyan
+	case lvl < WarnLvl:
+		return ansiBlue
+	case lvl < ErrorLvl:
+		return ansiYellow
+	case lvl < PanicLvl:
+		return ansiRed
+	default:
+		return ansiBoldRed
+	}
+}
+
+// shortCaller returns the last directory and the name of file, like "db/store.go".
+func shortCaller(file string) string {
+	slash := strings.LastIndex(file, "/")
+	if slash < 0 {
+		return file
+	}
+	if dir := strings.LastIndex(file[:slash], "/"); dir >= 0 {
+		return file[dir+1:]
+	}
+	return file
+}
+
+// appendPadding pads a column that is used characters wide to width with spaces, always leaving at
+// least one space after it.
+func appendPadding(buf []byte, used, width int) []byte {
+	buf = append(buf, ' ')
+	for i := used + 1; i < width; i++ {
+		buf = append(buf, ' ')
+	}
+	return buf
+}
+
+func useColor(out io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return forceColor() || isTerminal(out)
+}
+
+func forceColor() bool {
+	force := os.Getenv("FORCE_COLOR")
+	return force != "" && force != "0" && force != "false"
+}
+
+// isTerminal returns true if out is a character device, like a terminal.
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextFormatter(t *testing.T) {
+	e := Entry{
+		Time:    time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+		Level:   WarnLvl,
+		File:    "/my/test/file.go",
+		Line:    145,
+		Message: "retrying request",
+		Fields:  []Field{Int("attempt", 2), String("backend", "db-1")},
+	}
+	expected := "2015-07-02T13:28:42 [WARN] /my/test/file.go:145: retrying request attempt=2 backend=db-1\n"
+	if out := string(TextFormatter{}.Format(nil, &e)); out != expected {
+		t.Errorf("Expected output to be '%s', got '%s'\n", expected, out)
+	}
+}
+
+func TestConsoleFormatter(t *testing.T) {
+	cause := fmt.Errorf("dial tcp: %w", fmt.Errorf("connection refused"))
+	e := Entry{
+		Time:    time.Date(2015, time.July, 2, 13, 28, 42, 107000000, time.UTC),
+		Level:   WarnLvl,
+		File:    "/home/builder/go/src/github.com/acme/myapp/db/store.go",
+		Line:    145,
+		Message: "retrying request",
+		Fields:  []Field{Int("attempt", 2), Err(fmt.Errorf("ping failed: %w", cause))},
+	}
+	expected := "13:28:42.107 WARN  db/store.go:145         retrying request" + strings.Repeat(" ", 24) + "attempt=2 error=\"ping failed: dial tcp: connection refused\"\n" +
+		"    error: ping failed: dial tcp: connection refused\n" +
+		"      caused by: dial tcp: connection refused\n" +
+		"      caused by: connection refused\n"
+	if out := string((&ConsoleFormatter{}).Format(nil, &e)); out != expected {
+		t.Errorf("Expected output to be\n%s\ngot\n%s\n", expected, out)
+	}
+
+	e.Fields = nil
+	e.Level = ErrorLvl
+	expected = "13:28:42.107 \x1b[31mERROR\x1b[0m db/store.go:145         retrying request\n"
+	if out := string((&ConsoleFormatter{Color: true}).Format(nil, &e)); out != expected {
+		t.Errorf("Expected output to be %q, got %q\n", expected, out)
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	defer os.Setenv("NO_COLOR", os.Getenv("NO_COLOR"))
+	defer os.Setenv("FORCE_COLOR", os.Getenv("FORCE_COLOR"))
+	type colorTest struct {
+		noColor, forceColor string
+		color               bool
+		auto                Formatter
+	}
+	colorTests := []colorTest{
+		{color: false, auto: TextFormatter{}},
+		{forceColor: "1", color: true, auto: &ConsoleFormatter{Color: true}},
+		{forceColor: "0", color: false, auto: TextFormatter{}},
+		{noColor: "1", forceColor: "1", color: false, auto: &ConsoleFormatter{Color: false}},
+	}
+	for _, test := range colorTests {
+		os.Setenv("NO_COLOR", test.noColor)
+		os.Setenv("FORCE_COLOR", test.forceColor)
+		var buf bytes.Buffer
+		if color := NewConsoleFormatter(&buf).Color; color != test.color {
+			t.Errorf("Expected color to be %t, got %t from %+v\n", test.color, color, test)
+		}
+		if auto := AutoFormatter(&buf); fmt.Sprintf("%#v", auto) != fmt.Sprintf("%#v", test.auto) {
+			t.Errorf("Expected AutoFormatter to return %#v, got %#v from %+v\n", test.auto, auto, test)
+		}
+	}
+}
+
+func TestWithFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.WithFormatter(&ConsoleFormatter{}).Info("Test number", 1)
+	if !strings.Contains(buf.String(), " INFO  ") || !strings.HasSuffix(buf.String(), "Test number 1\n") {
+		t.Errorf("Expected console output, got `%s`\n", buf.String())
+	}
+}
@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"flag"
+	"sync"
+	"sync/atomic"
+)
+
+// TB is the part of testing.TB that ForTest uses. *testing.T and *testing.B both implement it. It's
+// declared here so programs that log don't have the testing package linked into them.
+type TB interface {
+	Log(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Failed() bool
+	Cleanup(func())
+}
+
+// ForTest returns a Logger for the code under test in t, set to DebugLvl, that writes through t.Log
+// instead of to stdout. The lines are held on to until the test finishes, and only written if it
+// failed, so passing tests stay quiet while failing ones come with everything that was logged. With
+// go test -v, lines are written as they're logged instead.
+//
+//	func TestCharge(t *testing.T) {
+//		svc := NewService(logging.ForTest(t))
+//		...
+//	}
+//
+// Logging an entry at ErrorLvl or above fails the test, since it usually means something went wrong
+// that the test didn't catch. Tests that exercise error paths on purpose can call ExpectErrors.
+//
+// Lines include the file and line they were logged from, honoring Helper and SkipPackages, so they
+// point at the code under test. t.Log prefixes them with a location of its own, which points into this
+// package, and can be ignored: testing.T's Helper method only hides the function that calls it, and
+// there are several of this package's functions between the code under test and t.Log, which can't
+// all call it. Lines held on to until the test fails are written by a cleanup function, long after the
+// code that logged them has returned. Entries logged after the test has finished, by goroutines it
+// left behind, are dropped, since t.Log can't be called anymore by then.
+func ForTest(t TB) Logger {
+	return forTest(t, testVerbose())
+}
+
+// testVerbose returns true if go test was run with -v. It looks the flag up instead of calling
+// testing.Verbose, so this package doesn't import testing.
+func testVerbose() bool {
+	f := flag.Lookup("test.v")
+	if f == nil {
+		return false
+	}
+	// go test -json sets it to "test2json", which is verbose too.
+	v := f.Value.String()
+	return v != "" && v != "false"
+}
+
+func forTest(t TB, verbose bool) Logger {
+	s := &testSink{t: t, verbose: verbose}
+	t.Cleanup(s.finish)
+	l, _ := New(DebugLvl, nil, "", nil)
+	return l.WithFlags(TimeNone | CallerShort).WithSink(s)
+}
+
+// ExpectErrors stops a Logger returned by ForTest, and every Logger derived from it, from failing the
+// test when entries at ErrorLvl or above are logged. It has no effect on other Loggers.
+func ExpectErrors(l Logger) {
+	if s, ok := l.sink().(*testSink); ok {
+		atomic.StoreInt32(&s.expectErrors, 1)
+	}
+}
+
+type testSink struct {
+	t            TB
+	verbose      bool
+	expectErrors int32
+
+	mu    sync.Mutex
+	lines []string
+	done  bool
+}
+
+func (s *testSink) WriteEntry(e *Entry, line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return nil
+	}
+	text := string(line)
+	if len(text) > 0 && text[len(text)-1] == '\n' {
+		text = text[:len(text)-1]
+	}
+	if s.verbose {
+		s.t.Log(text)
+	} else {
+		s.lines = append(s.lines, text)
+	}
+	if e.Level >= ErrorLvl && atomic.LoadInt32(&s.expectErrors) == 0 {
+		s.t.Errorf("unexpected %s entry logged: %s", e.Level, e.Message)
+	}
+	return nil
+}
+
+// finish writes the lines held on to if the test failed, and stops the sink from using t.
+func (s *testSink) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	if !s.t.Failed() {
+		return
+	}
+	for _, line := range s.lines {
+		s.t.Log(line)
+	}
+	s.lines = nil
+}
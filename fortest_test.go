@@ -0,0 +1,118 @@
+package logging
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// fakeT records what ForTest does with a test, instead of doing it to the real one.
+type fakeT struct {
+	logs     []string
+	errors   []string
+	cleanups []func()
+	failed   bool
+}
+
+func (f *fakeT) Log(args ...interface{}) {
+	f.logs = append(f.logs, fmt.Sprint(args...))
+}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+	f.failed = true
+}
+
+func (f *fakeT) Failed() bool {
+	return f.failed
+}
+
+func (f *fakeT) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeT) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestTestingTB(t *testing.T) {
+	var _ TB = t
+	var _ TB = &testing.B{}
+	if testVerbose() != testing.Verbose() {
+		t.Errorf("Expected testVerbose to be %v, like testing.Verbose, got %v instead\n", testing.Verbose(), testVerbose())
+	}
+}
+
+func TestForTestPassing(t *testing.T) {
+	ft := &fakeT{}
+	log := forTest(ft, false)
+	log.Debug("connecting")
+	log.Warn("slow response")
+	log.Trace("not logged")
+	if len(ft.logs) != 0 {
+		t.Errorf("Expected nothing to be logged before the test finishes, got %q instead\n", ft.logs)
+	}
+	ft.finish()
+	if len(ft.logs) != 0 || len(ft.errors) != 0 {
+		t.Errorf("Expected a passing test to stay quiet, got %q and %q instead\n", ft.logs, ft.errors)
+	}
+	log.Info("after the test")
+	if len(ft.logs) != 0 {
+		t.Errorf("Expected entries logged after the test finished to be dropped, got %q instead\n", ft.logs)
+	}
+}
+
+func TestForTestFailing(t *testing.T) {
+	ft := &fakeT{}
+	log := forTest(ft, false)
+	_, _, line, _ := runtime.Caller(0)
+	log.Infof("charging %d cents", 250)
+	ft.failed = true
+	ft.finish()
+	expected := []string{fmt.Sprintf("[INFO] fortest_test.go:%d: charging 250 cents", line+1)}
+	if fmt.Sprint(ft.logs) != fmt.Sprint(expected) {
+		t.Errorf("Expected a failing test to show %q, got %q instead\n", expected, ft.logs)
+	}
+}
+
+func TestForTestVerbose(t *testing.T) {
+	ft := &fakeT{}
+	log := forTest(ft, true)
+	log.Info("first")
+	if len(ft.logs) != 1 {
+		t.Errorf("Expected lines to be written right away with -v, got %q instead\n", ft.logs)
+	}
+	ft.finish()
+	if len(ft.logs) != 1 {
+		t.Errorf("Expected lines not to be written twice, got %q instead\n", ft.logs)
+	}
+}
+
+func TestForTestErrors(t *testing.T) {
+	ft := &fakeT{}
+	log := forTest(ft, false).AddFields(String("order", "A-1"))
+	log.Errorf("charge failed")
+	if len(ft.errors) != 1 || ft.errors[0] != "unexpected ERROR entry logged: charge failed" {
+		t.Errorf("Expected an unexpected error to fail the test, got %q instead\n", ft.errors)
+	}
+	ft.finish()
+	if len(ft.logs) != 1 {
+		t.Errorf("Expected the failed test to show what was logged, got %q instead\n", ft.logs)
+	}
+
+	ft = &fakeT{}
+	log = forTest(ft, false)
+	ExpectErrors(log.AddFields(String("order", "A-2")))
+	log.Error("charge failed")
+	ft.finish()
+	if len(ft.errors) != 0 || len(ft.logs) != 0 {
+		t.Errorf("Expected expected errors not to fail the test, got %q and %q instead\n", ft.errors, ft.logs)
+	}
+}
+
+func TestForTest(t *testing.T) {
+	log := ForTest(t)
+	log.Info("written through t.Log")
+}
@@ -0,0 +1,156 @@
+package grpclogging
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/DramaFever/go-logging"
+)
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that logs every call a server handles,
+// and makes a Logger for the call available to the handler:
+//
+//	srv := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(grpclogging.UnaryServerInterceptor(log)),
+//		grpc.ChainStreamInterceptor(grpclogging.StreamServerInterceptor(log)),
+//	)
+//
+// The Logger is l with the method and peer of the call added as the fields "grpc.method" and
+// "grpc.peer", and is stored in the handler's Context with SaveToContext, so handlers can retrieve it
+// with LogFromContext. Once the call is finished, an entry is written with the status code and
+// latency added as "grpc.code" and "grpc.latency". Calls that fail with codes.Internal or
+// codes.Unknown are logged at ErrorLvl, and so are sent to Sentry; every other call is logged at
+// InfoLvl.
+//
+// A handler that panics is logged at ErrorLvl and sent to Sentry too, with the stacktrace of the
+// panic, and the call fails with codes.Internal instead of crashing the server. The panic is reported
+// once: the entry for the finished call is written at InfoLvl.
+func UnaryServerInterceptor(l logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		log := l.AddFields(logging.String("grpc.method", info.FullMethod), logging.String("grpc.peer", peerAddr(ctx)))
+		defer func() {
+			r := recover()
+			if r != nil {
+				err = recovered(log, info.FullMethod, r)
+			}
+			finish(log, "finished unary call", start, err, r != nil)
+		}()
+		return handler(logging.SaveToContext(log, ctx), req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that logs every stream a server
+// handles, like UnaryServerInterceptor does for unary calls. The Context of the stream passed to the
+// handler carries the Logger for the stream.
+func StreamServerInterceptor(l logging.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		log := l.AddFields(logging.String("grpc.method", info.FullMethod), logging.String("grpc.peer", peerAddr(ss.Context())))
+		defer func() {
+			r := recover()
+			if r != nil {
+				err = recovered(log, info.FullMethod, r)
+			}
+			finish(log, "finished streaming call", start, err, r != nil)
+		}()
+		return handler(srv, &serverStream{ServerStream: ss, ctx: logging.SaveToContext(log, ss.Context())})
+	}
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that logs every call a client makes,
+// with the same fields and at the same Levels as UnaryServerInterceptor. The peer is the target the
+// client connection was dialed with. The Context of the call carries the Logger for the call, for
+// any other interceptors that want it.
+func UnaryClientInterceptor(l logging.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		log := l.AddFields(logging.String("grpc.method", method), logging.String("grpc.peer", cc.Target()))
+		err := invoker(logging.SaveToContext(log, ctx), method, req, reply, cc, opts...)
+		finish(log, "finished client unary call", start, err, false)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that logs every stream a client
+// opens, like UnaryClientInterceptor does for unary calls. A stream is finished, and logged, when
+// receiving from it fails, which is when the server has ended it with a status, or when it couldn't
+// be opened at all.
+func StreamClientInterceptor(l logging.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		log := l.AddFields(logging.String("grpc.method", method), logging.String("grpc.peer", cc.Target()))
+		cs, err := streamer(logging.SaveToContext(log, ctx), desc, cc, method, opts...)
+		if err != nil {
+			finish(log, "finished client streaming call", start, err, false)
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, log: log, start: start}, nil
+	}
+}
+
+// finish writes the entry for a finished call. If the call failed because its handler panicked, the
+// panic has already been logged at ErrorLvl and sent to Sentry, so the entry is written at InfoLvl.
+func finish(log logging.Logger, msg string, start time.Time, err error, panicked bool) {
+	code := status.Code(err)
+	log = log.AddFields(logging.String("grpc.code", code.String()), logging.Duration("grpc.latency", time.Since(start)))
+	switch {
+	case panicked:
+		log.Infof("%s: %v", msg, err)
+	case code == codes.Internal || code == codes.Unknown:
+		log.Errorf("%s: %v", msg, err)
+	default:
+		log.Info(msg)
+	}
+}
+
+// recovered logs a panic recovered from a handler, and returns the error the call fails with instead.
+func recovered(log logging.Logger, method string, r interface{}) error {
+	log.Errorf("panic handling %s: %v", method, r)
+	return status.Errorf(codes.Internal, "panic handling %s", method)
+}
+
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	return p.Addr.String()
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	log   logging.Logger
+	start time.Time
+	once  sync.Once
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.once.Do(func() {
+			if err == io.EOF {
+				finish(s.log, "finished client streaming call", s.start, nil, false)
+				return
+			}
+			finish(s.log, "finished client streaming call", s.start, err, false)
+		})
+	}
+	return err
+}
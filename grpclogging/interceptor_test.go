@@ -0,0 +1,200 @@
+package grpclogging
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/getsentry/raven-go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/DramaFever/go-logging"
+)
+
+type syncBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.String()
+}
+
+type recordingReporter struct {
+	sync.Mutex
+	packets []*raven.Packet
+}
+
+func (r *recordingReporter) Report(packet *raven.Packet, tags map[string]string) error {
+	r.Lock()
+	defer r.Unlock()
+	r.packets = append(r.packets, packet)
+	return nil
+}
+
+func (r *recordingReporter) Close() error {
+	return nil
+}
+
+func (r *recordingReporter) messages() []string {
+	r.Lock()
+	defer r.Unlock()
+	var messages []string
+	for _, packet := range r.packets {
+		messages = append(messages, packet.Message)
+	}
+	return messages
+}
+
+// healthServer answers Check according to the service it's asked about, and records whether the
+// Logger for the call could be retrieved from the handler's Context.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	logging.LogFromContext(ctx).Infof("checking %q", req.Service)
+	switch req.Service {
+	case "panic":
+		panic("database handle is nil")
+	case "internal":
+		return nil, status.Error(codes.Internal, "database unreachable")
+	case "missing":
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	logging.LogFromContext(stream.Context()).Infof("watching %q", req.Service)
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+func setup(t *testing.T, server, client logging.Logger) healthpb.HealthClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(server)),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(server)),
+	)
+	healthpb.RegisterHealthServer(srv, healthServer{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor(client)),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor(client)),
+	)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func newLogger(t *testing.T, out io.Writer, reporter logging.Reporter) logging.Logger {
+	l, err := logging.New(logging.InfoLvl, out, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	return l.WithFlags(logging.TimeNone | logging.CallerNone).WithReporter(reporter)
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	var serverOut, clientOut syncBuffer
+	serverReporter, clientReporter := &recordingReporter{}, &recordingReporter{}
+	client := setup(t, newLogger(t, &serverOut, serverReporter), newLogger(t, &clientOut, clientReporter))
+
+	type testCase struct {
+		service string
+		code    codes.Code
+		server  string
+		client  string
+	}
+	cases := []testCase{
+		{"", codes.OK,
+			`[INFO] checking "" grpc.method=/grpc.health.v1.Health/Check grpc.peer=bufconn` + "\n" +
+				`[INFO] finished unary call grpc.method=/grpc.health.v1.Health/Check grpc.peer=bufconn grpc.code=OK grpc.latency=`,
+			`[INFO] finished client unary call grpc.method=/grpc.health.v1.Health/Check grpc.peer=passthrough:///bufnet grpc.code=OK grpc.latency=`},
+		{"missing", codes.NotFound,
+			`[INFO] finished unary call grpc.method=/grpc.health.v1.Health/Check grpc.peer=bufconn grpc.code=NotFound grpc.latency=`,
+			`[INFO] finished client unary call grpc.method=/grpc.health.v1.Health/Check grpc.peer=passthrough:///bufnet grpc.code=NotFound grpc.latency=`},
+		{"internal", codes.Internal,
+			`[ERROR] finished unary call: rpc error: code = Internal desc = database unreachable grpc.method=/grpc.health.v1.Health/Check grpc.peer=bufconn grpc.code=Internal grpc.latency=`,
+			`[ERROR] finished client unary call: rpc error: code = Internal desc = database unreachable grpc.method=/grpc.health.v1.Health/Check grpc.peer=passthrough:///bufnet grpc.code=Internal grpc.latency=`},
+		{"panic", codes.Internal,
+			`[ERROR] panic handling /grpc.health.v1.Health/Check: database handle is nil grpc.method=/grpc.health.v1.Health/Check grpc.peer=bufconn` + "\n" +
+				`[INFO] finished unary call: rpc error: code = Internal desc = panic handling /grpc.health.v1.Health/Check grpc.method=/grpc.health.v1.Health/Check grpc.peer=bufconn grpc.code=Internal grpc.latency=`,
+			`[ERROR] finished client unary call: rpc error: code = Internal desc = panic handling /grpc.health.v1.Health/Check grpc.method=/grpc.health.v1.Health/Check grpc.peer=passthrough:///bufnet grpc.code=Internal grpc.latency=`},
+	}
+	for _, c := range cases {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: c.service})
+		if status.Code(err) != c.code {
+			t.Errorf("Expected checking %q to return %s, got %v instead\n", c.service, c.code, err)
+		}
+		if !strings.Contains(serverOut.String(), c.server) {
+			t.Errorf("Expected the server to log `%s`, got `%s` instead\n", c.server, serverOut.String())
+		}
+		if !strings.Contains(clientOut.String(), c.client) {
+			t.Errorf("Expected the client to log `%s`, got `%s` instead\n", c.client, clientOut.String())
+		}
+	}
+
+	expected := []string{
+		"finished unary call: rpc error: code = Internal desc = database unreachable",
+		"panic handling /grpc.health.v1.Health/Check: database handle is nil",
+	}
+	if messages := serverReporter.messages(); strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected the server to report %q, got %q instead\n", expected, messages)
+	}
+	if messages := clientReporter.messages(); len(messages) != 2 {
+		t.Errorf("Expected the client to report 2 events, got %q instead\n", messages)
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	var serverOut, clientOut syncBuffer
+	client := setup(t, newLogger(t, &serverOut, &recordingReporter{}), newLogger(t, &clientOut, &recordingReporter{}))
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{Service: "db"})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+	}
+
+	server := `[INFO] watching "db" grpc.method=/grpc.health.v1.Health/Watch grpc.peer=bufconn` + "\n" +
+		`[INFO] finished streaming call grpc.method=/grpc.health.v1.Health/Watch grpc.peer=bufconn grpc.code=OK grpc.latency=`
+	if !strings.Contains(serverOut.String(), server) {
+		t.Errorf("Expected the server to log `%s`, got `%s` instead\n", server, serverOut.String())
+	}
+	expected := `[INFO] finished client streaming call grpc.method=/grpc.health.v1.Health/Watch grpc.peer=passthrough:///bufnet grpc.code=OK grpc.latency=`
+	if !strings.Contains(clientOut.String(), expected) {
+		t.Errorf("Expected the client to log `%s`, got `%s` instead\n", expected, clientOut.String())
+	}
+}
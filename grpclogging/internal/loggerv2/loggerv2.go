@@ -0,0 +1,66 @@
+// Package loggerv2 holds the grpclog.LoggerV2 that grpclogging.NewLoggerV2 returns. It's a package of
+// its own so that its frames can be skipped with logging.SkipPackages when deciding where an entry
+// was logged from, without skipping the interceptors in grpclogging too.
+package loggerv2
+
+import (
+	"github.com/DramaFever/go-logging"
+)
+
+// Logger is a grpclog.LoggerV2 that writes to L.
+type Logger struct {
+	L logging.Logger
+}
+
+func (g Logger) Info(args ...interface{}) {
+	g.L.Info(args...)
+}
+
+func (g Logger) Infoln(args ...interface{}) {
+	g.L.Info(args...)
+}
+
+func (g Logger) Infof(format string, args ...interface{}) {
+	g.L.Infof(format, args...)
+}
+
+func (g Logger) Warning(args ...interface{}) {
+	g.L.Warn(args...)
+}
+
+func (g Logger) Warningln(args ...interface{}) {
+	g.L.Warn(args...)
+}
+
+func (g Logger) Warningf(format string, args ...interface{}) {
+	g.L.Warnf(format, args...)
+}
+
+func (g Logger) Error(args ...interface{}) {
+	g.L.Error(args...)
+}
+
+func (g Logger) Errorln(args ...interface{}) {
+	g.L.Error(args...)
+}
+
+func (g Logger) Errorf(format string, args ...interface{}) {
+	g.L.Errorf(format, args...)
+}
+
+func (g Logger) Fatal(args ...interface{}) {
+	g.L.Fatal(args...)
+}
+
+func (g Logger) Fatalln(args ...interface{}) {
+	g.L.Fatal(args...)
+}
+
+func (g Logger) Fatalf(format string, args ...interface{}) {
+	g.L.Fatalf(format, args...)
+}
+
+func (g Logger) V(l int) bool {
+	// Logger.V counts down from DebugLvl instead of InfoLvl.
+	return g.L.V(l - int(logging.InfoLvl-logging.DebugLvl)).Enabled()
+}
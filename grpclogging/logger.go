@@ -0,0 +1,44 @@
+// Package grpclogging connects gRPC to github.com/DramaFever/go-logging. It provides a grpclog.LoggerV2,
+// so that gRPC's own logs are written by a Logger, and interceptors for servers and clients that log
+// every call and report the calls that fail unexpectedly to Sentry.
+package grpclogging
+
+import (
+	"reflect"
+	"sync"
+
+	"google.golang.org/grpc/grpclog"
+
+	"github.com/DramaFever/go-logging"
+	"github.com/DramaFever/go-logging/grpclogging/internal/loggerv2"
+)
+
+var skipOnce sync.Once
+
+// NewLoggerV2 returns a grpclog.LoggerV2 that writes gRPC's logs to l. Install it before doing anything
+// else with gRPC, as grpclog.SetLoggerV2 requires:
+//
+//	func main() {
+//		log, err := logging.LogToStdout(logging.InfoLvl, dsn, nil)
+//		...
+//		grpclog.SetLoggerV2(grpclogging.NewLoggerV2(log.WithLevel(logging.WarnLvl)))
+//
+// gRPC's Info, Warning, Error, and Fatal logs are written at InfoLvl, WarnLvl, ErrorLvl, and FatalLvl,
+// so its warnings and errors are sent to Sentry along with everything else's. gRPC is chatty at
+// InfoLvl; giving it a Logger with a higher Level, or a level override for
+// "google.golang.org/grpc", is usually a good idea. gRPC's verbosity levels count down from its Info
+// logs: V(n) is true when l writes entries at InfoLvl-n, so V(0) is true whenever gRPC's Info logs are
+// written, and V(2) when l's Level is InfoLvl-2 or lower.
+//
+// Entries are attributed to the code in gRPC that logged them, rather than to gRPC's logging package.
+func NewLoggerV2(l logging.Logger) grpclog.LoggerV2 {
+	skipOnce.Do(func() {
+		// The LoggerV2 lives in a package of its own so it can be skipped along with gRPC's logging
+		// packages, rather than calling logging.Helper in every method, which would make V expensive
+		// even when it returns false. The interceptors are left alone, so their entries are still
+		// attributed to them, and aren't subject to level overrides for gRPC.
+		logging.SkipPackages("google.golang.org/grpc/grpclog", "google.golang.org/grpc/internal/grpclog",
+			reflect.TypeOf(loggerv2.Logger{}).PkgPath())
+	})
+	return loggerv2.Logger{L: l}
+}
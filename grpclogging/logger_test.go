@@ -0,0 +1,76 @@
+package grpclogging
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"google.golang.org/grpc/grpclog"
+
+	"github.com/DramaFever/go-logging"
+)
+
+func TestLoggerV2(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := logging.New(logging.DebugLvl-2, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	logger := NewLoggerV2(l.WithFlags(logging.TimeNone | logging.CallerShort))
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.Infof("subchannel %d created", 4)
+	logger.Warningln("transport closing")
+	logger.Error("connection reset")
+
+	expected := fmt.Sprintf("[INFO] logger_test.go:%d: subchannel 4 created\n", line+1) +
+		fmt.Sprintf("[WARN] logger_test.go:%d: transport closing\n", line+2) +
+		fmt.Sprintf("[ERROR] logger_test.go:%d: connection reset\n", line+3)
+	if buf.String() != expected {
+		t.Errorf("Expected `%s`, got `%s` instead\n", expected, buf.String())
+	}
+
+	if !logger.V(6) {
+		t.Errorf("Expected V(6) to be enabled\n")
+	}
+	if logger.V(7) {
+		t.Errorf("Expected V(7) to be disabled\n")
+	}
+}
+
+func TestLoggerV2Verbosity(t *testing.T) {
+	l, err := logging.New(logging.InfoLvl, &bytes.Buffer{}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	logger := NewLoggerV2(l)
+	if !logger.V(0) {
+		t.Errorf("Expected V(0) to be enabled at InfoLvl\n")
+	}
+	if logger.V(1) {
+		t.Errorf("Expected V(1) to be disabled at InfoLvl\n")
+	}
+	logger = NewLoggerV2(l.WithLevel(logging.InfoLvl - 2))
+	if !logger.V(2) || logger.V(3) {
+		t.Errorf("Expected V(2), but not V(3), to be enabled at InfoLvl-2\n")
+	}
+}
+
+func TestLoggerV2Component(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := logging.New(logging.InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	grpclog.SetLoggerV2(NewLoggerV2(l.WithFlags(logging.TimeNone | logging.CallerShort)))
+	defer grpclog.SetLoggerV2(grpclog.NewLoggerV2(&bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}))
+
+	_, _, line, _ := runtime.Caller(0)
+	grpclog.Component("balancer").Warningf("picker returned %d", 0)
+
+	expected := fmt.Sprintf("[WARN] logger_test.go:%d: [balancer] picker returned 0\n", line+1)
+	if buf.String() != expected {
+		t.Errorf("Expected `%s`, got `%s` instead\n", expected, buf.String())
+	}
+}
@@ -0,0 +1,242 @@
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/getsentry/raven-go"
+)
+
+const (
+	// TraceLvl indicates extremely verbose messages, like the contents of every packet read
+	TraceLvl Level = -8
+	// DebugLvl indicates messages for local development
+	DebugLvl Level = -4
+	// InfoLvl indicates non-error messages useful to Ops
+	InfoLvl Level = 0
+	// WarnLvl indicates recoverable error messages
+	WarnLvl Level = 4
+	// ErrorLvl indicates non-recoverable error messages
+	ErrorLvl Level = 8
+	// PanicLvl indicates messages logged immediately before panicking
+	PanicLvl Level = 12
+	// FatalLvl indicates messages logged immediately before the process exits
+	FatalLvl Level = 16
+)
+
+// Level is the severity of a log statement, and the threshold used to constrain which logs are written
+// in which environments. Levels are ordered: a Logger set to a Level writes every statement logged at
+// that Level or at a more severe one, and discards everything less severe. So when set to DebugLvl,
+// everything but TraceLvl statements is written, and when set to ErrorLvl, only ErrorLvl, PanicLvl, and
+// FatalLvl statements are written.
+//
+// The predefined Levels are spaced out so that applications can give names to the Levels in between
+// using RegisterLevel. A Level that has not been named is displayed relative to the closest named Level
+// below it, like "INFO+2".
+//
+// Choosing which Level to use is a bit of an art. As a guideline: anything that Ops cannot use to
+// investigate an issue in production should be at DebugLvl. ErrorLvl is for fatal errors that result
+// in a 500. WarnLvl is for recoverable errors that you can gracefully degrade from, but which are not
+// expected occurrences.
+//
+// FatalLvl and PanicLvl are only used by the Fatal and Panic families of methods, which stop the
+// program (or the goroutine) after logging. Reach for them when there's no way to continue at all,
+// such as a missing required configuration value at startup.
+//
+// An example of an ErrorLvl scenario may be that your binary can't reach the database.
+// An example of a WarnLvl scenario may be that you temporarily couldn't reach an external service, but
+// are retrying. (If your retrying logic fails, escalate it to an ErrorLvl.)
+// An example of an InfoLvl scenario may be the port and address a host is listening on.
+// An example of a DebugLvl scenario may be an incoming request, a cache miss, the response from an
+// external service, the database query ran, etc. Things that are useful when writing software, but
+// too noisy to have on in production. TraceLvl is for things that are too noisy even for that.
+type Level int32
+
+// levels holds the names of every Level that has one. The predefined Levels are always present.
+var levels = struct {
+	sync.RWMutex
+	names  map[Level]string
+	byName map[string]Level
+}{
+	names: map[Level]string{
+		TraceLvl: "TRACE",
+		DebugLvl: "DEBUG",
+		InfoLvl:  "INFO",
+		WarnLvl:  "WARN",
+		ErrorLvl: "ERROR",
+		PanicLvl: "PANIC",
+		FatalLvl: "FATAL",
+	},
+	byName: map[string]Level{
+		"TRACE": TraceLvl,
+		"DEBUG": DebugLvl,
+		"INFO":  InfoLvl,
+		"WARN":  WarnLvl,
+		"ERROR": ErrorLvl,
+		"PANIC": PanicLvl,
+		"FATAL": FatalLvl,
+	},
+}
+
+// levelAliases are alternative spellings ParseLevel accepts for the predefined Levels, on top of their names.
+var levelAliases = map[string]Level{
+	"WARNING": WarnLvl,
+	"ERR":     ErrorLvl,
+}
+
+// RegisterLevel gives lvl a name, which will be used when displaying it. Names are matched without
+// regard to case, and neither a name nor a Level can be registered twice. RegisterLevel is meant to be
+// called during program initialization, before any Loggers write at lvl.
+func RegisterLevel(name string, lvl Level) error {
+	if name == "" || strings.ContainsAny(name, " \t\n+-") {
+		return fmt.Errorf("logging: invalid level name %q", name)
+	}
+	levels.Lock()
+	defer levels.Unlock()
+	if existing, ok := levels.names[lvl]; ok {
+		return fmt.Errorf("logging: level %d is already registered as %s", int32(lvl), existing)
+	}
+	if _, ok := levels.byName[strings.ToUpper(name)]; ok {
+		return errors.New("logging: level name " + name + " is already registered")
+	}
+	levels.names[lvl] = name
+	levels.byName[strings.ToUpper(name)] = lvl
+	return nil
+}
+
+// String returns the name of l. If l has not been named, it is described by its distance from the
+// closest named Level below it, like "DEBUG+1". Levels below every named Level are described by their
+// distance from the lowest one, like "TRACE-4".
+func (l Level) String() string {
+	levels.RLock()
+	defer levels.RUnlock()
+	if name, ok := levels.names[l]; ok {
+		return name
+	}
+	var below, lowest Level
+	var foundBelow, foundLowest bool
+	for lvl := range levels.names {
+		if lvl < l && (!foundBelow || lvl > below) {
+			below, foundBelow = lvl, true
+		}
+		if !foundLowest || lvl < lowest {
+			lowest, foundLowest = lvl, true
+		}
+	}
+	if !foundBelow {
+		return fmt.Sprintf("%s%d", levels.names[lowest], int32(l-lowest))
+	}
+	return fmt.Sprintf("%s+%d", levels.names[below], int32(l-below))
+}
+
+// Enabled returns true if a statement logged at other should be written by a Logger set to l. That is
+// the case when other is at least as severe as l.
+func (l Level) Enabled(other Level) bool {
+	return other >= l
+}
+
+func (l Level) asSentryLevel() raven.Severity {
+	switch {
+	case l < InfoLvl:
+		return raven.DEBUG
+	case l < WarnLvl:
+		return raven.INFO
+	case l < ErrorLvl:
+		return raven.WARNING
+	case l < PanicLvl:
+		return raven.ERROR
+	default:
+		return raven.FATAL
+	}
+}
+
+// ParseLevel returns the Level named by s. Names are matched without regard to case or surrounding
+// whitespace, so "warn", "WARN", and " Warn " all return WarnLvl. A few common aliases, like "warning"
+// and "err", are accepted too, as are names registered with RegisterLevel and the relative names that
+// String produces for unnamed Levels, like "DEBUG+1". Anything else returns an error.
+func ParseLevel(s string) (Level, error) {
+	name := strings.ToUpper(strings.TrimSpace(s))
+	var offset int
+	if i := strings.LastIndexAny(name, "+-"); i > 0 {
+		n, err := strconv.Atoi(name[i:])
+		if err != nil {
+			return 0, fmt.Errorf("logging: unknown level %q", s)
+		}
+		name, offset = name[:i], n
+	}
+	levels.RLock()
+	lvl, ok := levels.byName[name]
+	levels.RUnlock()
+	if !ok {
+		lvl, ok = levelAliases[name]
+	}
+	if !ok {
+		return 0, fmt.Errorf("logging: unknown level %q", s)
+	}
+	return lvl + Level(offset), nil
+}
+
+// LevelFromEnv returns the Level named by the environment variable key, using ParseLevel. If the
+// variable is unset or empty, fallback is returned. If it is set to something that isn't a Level, an
+// error is returned, so a typo in a deployment doesn't go unnoticed.
+func LevelFromEnv(key string, fallback Level) (Level, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback, nil
+	}
+	lvl, err := ParseLevel(val)
+	if err != nil {
+		return fallback, fmt.Errorf("logging: %s=%q is not a valid level", key, val)
+	}
+	return lvl, nil
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding l as its name.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, decoding the name of a Level with ParseLevel.
+// This is also what YAML and TOML decoders that honor encoding.TextUnmarshaler use, so Levels can be
+// read straight out of configuration files.
+func (l *Level) UnmarshalText(text []byte) error {
+	lvl, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = lvl
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding l as a JSON string containing its name.
+func (l Level) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts a JSON string containing the name of a Level,
+// as ParseLevel does, or a JSON number containing its severity.
+func (l *Level) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		return l.UnmarshalText([]byte(name))
+	}
+	var severity int32
+	if err := json.Unmarshal(data, &severity); err != nil {
+		return fmt.Errorf("logging: level must be a string or a number, got %s", data)
+	}
+	*l = Level(severity)
+	return nil
+}
+
+// Set implements flag.Value, so a Level can be configured with flag.Var:
+//
+//	lvl := logging.InfoLvl
+//	flag.Var(&lvl, "log-level", "minimum level to log")
+func (l *Level) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,183 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLevelString(t *testing.T) {
+	stringTests := map[Level]string{
+		TraceLvl:     "TRACE",
+		DebugLvl:     "DEBUG",
+		InfoLvl:      "INFO",
+		WarnLvl:      "WARN",
+		ErrorLvl:     "ERROR",
+		PanicLvl:     "PANIC",
+		FatalLvl:     "FATAL",
+		InfoLvl + 2:  "INFO+2",
+		WarnLvl - 1:  "INFO+3",
+		TraceLvl - 4: "TRACE-4",
+		FatalLvl + 9: "FATAL+9",
+	}
+	for lvl, expected := range stringTests {
+		if lvl.String() != expected {
+			t.Errorf("Expected level %d to be %s, got %s instead\n", int32(lvl), expected, lvl.String())
+		}
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	notice := InfoLvl + 2
+	err := RegisterLevel("NOTICE", notice)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defer func() {
+		levels.Lock()
+		delete(levels.names, notice)
+		delete(levels.byName, "NOTICE")
+		levels.Unlock()
+	}()
+	if notice.String() != "NOTICE" {
+		t.Errorf("Expected registered level to be NOTICE, got %s instead\n", notice)
+	}
+	if (InfoLvl + 3).String() != "NOTICE+1" {
+		t.Errorf("Expected level above NOTICE to be NOTICE+1, got %s instead\n", InfoLvl+3)
+	}
+	invalid := map[string]Level{
+		"notice":     InfoLvl + 1,
+		"AUDIT":      notice,
+		"":           InfoLvl + 1,
+		"NOT ICE":    InfoLvl + 1,
+		"NOTICE+1":   InfoLvl + 1,
+		"IMPORTANT":  WarnLvl,
+		"UNRELATED-": InfoLvl + 1,
+	}
+	for name, lvl := range invalid {
+		if err := RegisterLevel(name, lvl); err == nil {
+			t.Errorf("Expected an error registering %q as level %d\n", name, int32(lvl))
+		}
+	}
+
+	var buf bytes.Buffer
+	log, err := New(notice, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Infof("Not written")
+	log.Logf(notice, "Written at %s", "notice")
+	if strings.Contains(buf.String(), "Not written") {
+		t.Errorf("Expected InfoLvl to be excluded from a NOTICE Logger, got `%s`\n", buf.String())
+	}
+	if !strings.Contains(buf.String(), "[NOTICE]") || !strings.HasSuffix(buf.String(), "Written at notice\n") {
+		t.Errorf("Expected a NOTICE line, got `%s`\n", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	parseTests := map[string]Level{
+		"TRACE":     TraceLvl,
+		"debug":     DebugLvl,
+		" Info ":    InfoLvl,
+		"warn":      WarnLvl,
+		"Warning":   WarnLvl,
+		"ERROR":     ErrorLvl,
+		"err":       ErrorLvl,
+		"panic":     PanicLvl,
+		"fatal":     FatalLvl,
+		"DEBUG+1":   DebugLvl + 1,
+		"info-2":    InfoLvl - 2,
+		"TRACE-4":   TraceLvl - 4,
+		"warning+2": WarnLvl + 2,
+	}
+	for in, expected := range parseTests {
+		lvl, err := ParseLevel(in)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %q: %+v\n", in, err)
+			continue
+		}
+		if lvl != expected {
+			t.Errorf("Expected %q to parse as %s, got %s instead\n", in, expected, lvl)
+		}
+	}
+	for _, in := range []string{"", "verbose", "DEGUB", "+1", "info+", "info+x", "5"} {
+		if lvl, err := ParseLevel(in); err == nil {
+			t.Errorf("Expected an error parsing %q, got %s instead\n", in, lvl)
+		}
+	}
+}
+
+func TestLevelJSON(t *testing.T) {
+	type config struct {
+		Level Level `json:"level"`
+	}
+	out, err := json.Marshal(config{Level: WarnLvl})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if string(out) != `{"level":"WARN"}` {
+		t.Errorf("Expected WarnLvl to marshal as `{\"level\":\"WARN\"}`, got `%s` instead\n", out)
+	}
+	unmarshalTests := map[string]Level{
+		`{"level":"warning"}`: WarnLvl,
+		`{"level":"DEBUG+1"}`: DebugLvl + 1,
+		`{"level":-8}`:        TraceLvl,
+	}
+	for in, expected := range unmarshalTests {
+		var c config
+		if err := json.Unmarshal([]byte(in), &c); err != nil {
+			t.Errorf("Unexpected error unmarshaling `%s`: %+v\n", in, err)
+			continue
+		}
+		if c.Level != expected {
+			t.Errorf("Expected `%s` to unmarshal as %s, got %s instead\n", in, expected, c.Level)
+		}
+	}
+	for _, in := range []string{`{"level":"loud"}`, `{"level":true}`} {
+		var c config
+		if err := json.Unmarshal([]byte(in), &c); err == nil {
+			t.Errorf("Expected an error unmarshaling `%s`, got %s instead\n", in, c.Level)
+		}
+	}
+}
+
+func TestLevelFlag(t *testing.T) {
+	lvl := InfoLvl
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Var(&lvl, "log-level", "minimum level to log")
+	if err := fs.Parse([]string{"-log-level", "debug"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if lvl != DebugLvl {
+		t.Errorf("Expected flag to set DebugLvl, got %s instead\n", lvl)
+	}
+	if err := fs.Parse([]string{"-log-level", "chatty"}); err == nil {
+		t.Error("Expected an error setting the flag to an unknown level")
+	}
+}
+
+func TestLevelFromEnv(t *testing.T) {
+	const key = "GO_LOGGING_TEST_LEVEL"
+	defer os.Unsetenv(key)
+
+	os.Unsetenv(key)
+	lvl, err := LevelFromEnv(key, ErrorLvl)
+	if err != nil || lvl != ErrorLvl {
+		t.Errorf("Expected unset variable to fall back to ERROR, got %s, %+v instead\n", lvl, err)
+	}
+	os.Setenv(key, "warning")
+	lvl, err = LevelFromEnv(key, ErrorLvl)
+	if err != nil || lvl != WarnLvl {
+		t.Errorf("Expected WARN, got %s, %+v instead\n", lvl, err)
+	}
+	os.Setenv(key, "debgu")
+	if lvl, err = LevelFromEnv(key, ErrorLvl); err == nil {
+		t.Errorf("Expected an error for an invalid level, got %s instead\n", lvl)
+	}
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// LevelVar is a Level that can be changed while the program is running. Loggers set up with
+// WithLevelVar consult it on every statement, so a single LevelVar can switch a whole service (and
+// every Logger derived from its root Logger) to DebugLvl and back without restarting it. A LevelVar is
+// safe for concurrent use, and its zero value is InfoLvl.
+type LevelVar struct {
+	lvl int32
+}
+
+// NewLevelVar returns a LevelVar set to lvl.
+func NewLevelVar(lvl Level) *LevelVar {
+	v := new(LevelVar)
+	v.Set(lvl)
+	return v
+}
+
+// Level returns the current Level of v.
+func (v *LevelVar) Level() Level {
+	return Level(atomic.LoadInt32(&v.lvl))
+}
+
+// Set changes the Level of v to lvl.
+func (v *LevelVar) Set(lvl Level) {
+	atomic.StoreInt32(&v.lvl, int32(lvl))
+}
+
+// String returns the name of the current Level of v.
+func (v *LevelVar) String() string {
+	return v.Level().String()
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding the current Level of v as its name.
+func (v *LevelVar) MarshalText() ([]byte, error) {
+	return v.Level().MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, setting v to the Level named by text.
+func (v *LevelVar) UnmarshalText(text []byte) error {
+	var lvl Level
+	if err := lvl.UnmarshalText(text); err != nil {
+		return err
+	}
+	v.Set(lvl)
+	return nil
+}
+
+type levelPayload struct {
+	Level *Level `json:"level,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ServeHTTP makes v an http.Handler, meant to be mounted on an admin port. A GET request responds with
+// the current Level, like {"level":"INFO"}. A PUT request with a body in the same format changes the
+// Level and responds with the new one; the level can be a name ParseLevel understands or a number.
+// Any other method is refused.
+func (v *LevelVar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		var req levelPayload
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(levelPayload{Error: err.Error()})
+			return
+		}
+		if req.Level == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(levelPayload{Error: "logging: level is required"})
+			return
+		}
+		v.Set(*req.Level)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		enc.Encode(levelPayload{Error: "logging: method " + r.Method + " not allowed"})
+		return
+	}
+	lvl := v.Level()
+	enc.Encode(levelPayload{Level: &lvl})
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLevelVarShared(t *testing.T) {
+	var buf bytes.Buffer
+	v := NewLevelVar(InfoLvl)
+	log, err := New(ErrorLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithLevelVar(v)
+	child := log.AddTags(map[string]string{"component": "test"}).WithOutput(&buf)
+
+	child.Debug("Before")
+	if buf.Len() != 0 {
+		t.Errorf("Expected DebugLvl to be excluded, got `%s`\n", buf.String())
+	}
+	v.Set(DebugLvl)
+	if child.GetLevel() != DebugLvl {
+		t.Errorf("Expected child Logger to follow the LevelVar to DEBUG, got %s instead\n", child.GetLevel())
+	}
+	child.Debug("After")
+	if !strings.HasSuffix(buf.String(), "After\n") {
+		t.Errorf("Expected DebugLvl to be written after changing the LevelVar, got `%s`\n", buf.String())
+	}
+
+	detached := child.WithLevel(WarnLvl)
+	v.Set(TraceLvl)
+	if detached.GetLevel() != WarnLvl {
+		t.Errorf("Expected WithLevel to stop following the LevelVar, got %s instead\n", detached.GetLevel())
+	}
+}
+
+func TestLevelVarConcurrent(t *testing.T) {
+	v := NewLevelVar(InfoLvl)
+	log, err := New(InfoLvl, &bytes.Buffer{}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithLevelVar(v)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				v.Set(DebugLvl)
+				v.Set(InfoLvl)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				log.Debugf("Test number %d", j)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLevelVarHandler(t *testing.T) {
+	v := NewLevelVar(InfoLvl)
+	type handlerTest struct {
+		method, body string
+		status       int
+		response     string
+		level        Level
+	}
+	handlerTests := []handlerTest{
+		{method: "GET", status: http.StatusOK, response: `{"level":"INFO"}`, level: InfoLvl},
+		{method: "PUT", body: `{"level":"debug"}`, status: http.StatusOK, response: `{"level":"DEBUG"}`, level: DebugLvl},
+		{method: "GET", status: http.StatusOK, response: `{"level":"DEBUG"}`, level: DebugLvl},
+		{method: "PUT", body: `{"level":4}`, status: http.StatusOK, response: `{"level":"WARN"}`, level: WarnLvl},
+		{method: "PUT", body: `{"level":"lots"}`, status: http.StatusBadRequest, level: WarnLvl},
+		{method: "PUT", body: `{}`, status: http.StatusBadRequest, level: WarnLvl},
+		{method: "POST", body: `{"level":"debug"}`, status: http.StatusMethodNotAllowed, level: WarnLvl},
+	}
+	for _, test := range handlerTests {
+		req := httptest.NewRequest(test.method, "/log/level", strings.NewReader(test.body))
+		resp := httptest.NewRecorder()
+		v.ServeHTTP(resp, req)
+		if resp.Code != test.status {
+			t.Errorf("Expected status %d, got %d from %+v\n", test.status, resp.Code, test)
+		}
+		if test.response != "" && strings.TrimSpace(resp.Body.String()) != test.response {
+			t.Errorf("Expected response `%s`, got `%s` from %+v\n", test.response, resp.Body.String(), test)
+		}
+		if v.Level() != test.level {
+			t.Errorf("Expected level to be %s, got %s from %+v\n", test.level, v.Level(), test)
+		}
+	}
+}
@@ -6,8 +6,7 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"runtime"
-	"sync"
+	"strings"
 	"time"
 
 	"golang.org/x/net/context"
@@ -16,79 +15,36 @@ import (
 )
 
 const (
-	// DebugLvl indicates messages for local development
-	DebugLvl Level = "DEBUG"
-	// InfoLvl indicates non-error messages useful to Ops
-	InfoLvl Level = "INFO"
-	// WarnLvl indicates recoverable error messages
-	WarnLvl Level = "WARN"
-	// ErrorLvl indicates non-recoverable error messages
-	ErrorLvl Level = "ERROR"
-
 	contextKey = "github.com/DramaFever/go-logging#Logger"
 )
 
-// Level is a threshold used to constrain which logs are written in which environments.
-// When set to DebugLvl, all output is written.
-// When set to InfoLvl, all output except DebugLvl statements are written.
-// When set to WarnLvl, all output except DebugLvl and InfoLvl statements are written.
-// When set to ErrorLvl, all output except DebugLvl, InfoLvl, and WarnLvl statements are written.
-// When set to any other value, all output is written.
-//
-// Choosing which Level to use is a bit of an art. As a guideline: anything that Ops cannot use to
-// investigate an issue in production should be at DebugLvl. ErrorLvl is for fatal errors that result
-// in a 500. WarnLvl is for recoverable errors that you can gracefully degrade from, but which are not
-// expected occurrences.
-//
-// An example of an ErrorLvl scenario may be that your binary can't reach the database.
-// An example of a WarnLvl scenario may be that you temporarily couldn't reach an external service, but
-// are retrying. (If your retrying logic fails, escalate it to an ErrorLvl.)
-// An example of an InfoLvl scenario may be the port and address a host is listening on.
-// An example of a DebugLvl scenario may be an incoming request, a cache miss, the response from an
-// external service, the database query ran, etc. Things that are useful when writing software, but
-// too noisy to have on in production.
-type Level string
-
-// includes returns true if l "includes" other. l includes other when a message logged at other's Level
-// should be included in a log file that requires at least l severity.
-func (l Level) includes(other Level) bool {
-	switch l {
-	case InfoLvl:
-		return other != DebugLvl
-	case WarnLvl:
-		return other != DebugLvl && other != InfoLvl
-	case ErrorLvl:
-		return other != DebugLvl && other != InfoLvl && other != WarnLvl
-	default:
-		return true
-	}
-}
-
-func (l Level) asSentryLevel() raven.Severity {
-	switch l {
-	case DebugLvl:
-		return raven.DEBUG
-	case InfoLvl:
-		return raven.INFO
-	case WarnLvl:
-		return raven.WARNING
-	case ErrorLvl:
-		return raven.ERROR
-	default:
-		return raven.ERROR
-	}
-}
+// exit is called by the Fatal family of methods once the Logger has been closed. It's a variable
+// so tests can observe the exit instead of the test binary dying.
+var exit = os.Exit
 
 // Logger is an instance of a log handler, used to write files to the designated output
 // if they meet the specified Level. It is concurrency-safe. Each Logger should have its
 // Close method called when you're done with it.
+//
+// Loggers are lightweight values. The output, the Sentry client, and the lock that keeps lines from
+// interleaving are shared by every Logger derived from the one New returned, and are only closed when
+// that Logger is closed. See Close for the details.
 type Logger struct {
 	level           Level
-	out             io.Writer
-	sentry          *raven.Client
+	levelVar        *LevelVar
+	overrides       *levelOverrides
+	sampler         *sampler
+	collapser       *collapser
+	redactor        *Redactor
+	escaping        Escaping
+	flags           Flags
+	formatter       Formatter
+	clock           Clock
+	fields          []Field
+	core            *core
+	owns            *core
 	calldepth       int
-	buf             []byte
-	flock           *sync.Mutex
+	release         string
 	tags            map[string]string
 	meta            []raven.Interface
 	packagePrefixes []string
@@ -118,15 +74,17 @@ func LogToStdout(level Level, sentry string, sentryTags map[string]string) (Logg
 }
 
 // New creates a new Logger that writes to the io.Writer specified. If the io.Writer is an io.WriteCloser,
-// it will be automatically closed when the Logger's Close method is called.
+// it will be automatically closed when the Logger's Close method is called, unless it's os.Stdout or
+// os.Stderr, which are left open for the rest of the program.
 //
 // If sentry is non-empty, it will be used as a DSN to connect to a Sentry error collector. The sentryTags
 // are a key/value mapping that will be applied to your Sentry errors. You can use them to set things like
 // the version of your software running, etc.
 func New(level Level, out io.Writer, sentry string, sentryTags map[string]string) (Logger, error) {
-	var sentryClient *raven.Client
+	var reporter Reporter
 	var err error
 	if sentry != "" {
+		var sentryClient *raven.Client
 		tr := &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 		}
@@ -134,39 +92,32 @@ func New(level Level, out io.Writer, sentry string, sentryTags map[string]string
 		sentryClient, err = raven.NewClient(sentry, sentryTags)
 		if sentryClient != nil {
 			sentryClient.Transport = &raven.HTTPTransport{Http: *httpClient}
+			reporter = SentryReporter(sentryClient)
 		}
 	}
+	var sink Sink
+	if out != nil {
+		sink = closingWriterSink(out)
+	}
+	c := newCore(sink, reporter)
 	return Logger{
-		level:  level,
-		out:    out,
-		sentry: sentryClient,
-		flock:  new(sync.Mutex),
-		tags:   map[string]string{},
+		level: level,
+		core:  c,
+		owns:  c,
+		tags:  map[string]string{},
 	}, err
 }
 
 // LogFromContext returns a Logger that is ready to use from the Context provided. In a case where a Logger
-// has not been stored in the Context previously (using SaveToContext), LogFromContext will fall back on a
-// Logger that writes to stderr, is set to InfoLvl, and has no Sentry configuration. This is to help debug
-// Logger configuration errors; in production, SaveToContext should always be used before trying to retrieve
-// the Logger wtih LogFromContext. Normally, SaveToContext should be called as part of application startup
-// when the Logger is instantiated.
+// has not been stored in the Context previously (using SaveToContext), LogFromContext will fall back on the
+// default Logger returned by Default, which unless SetDefault has been called writes to stderr, is set to
+// InfoLvl, and has no Sentry configuration. This is to help debug Logger configuration errors; in
+// production, SaveToContext should always be used before trying to retrieve the Logger wtih LogFromContext.
+// Normally, SaveToContext should be called as part of application startup when the Logger is instantiated.
 func LogFromContext(c context.Context) Logger {
-	ctxVal := c.Value(contextKey)
-	if ctxVal == nil {
-		logger, err := New(InfoLvl, os.Stderr, "", nil)
-		if err != nil {
-			panic(err.Error())
-		}
-		return logger
-	}
-	logger, ok := ctxVal.(Logger)
+	logger, ok := c.Value(contextKey).(Logger)
 	if !ok {
-		logger, err := New(InfoLvl, os.Stderr, "", nil)
-		if err != nil {
-			panic(err.Error())
-		}
-		return logger
+		return Default()
 	}
 	return logger
 }
@@ -180,9 +131,14 @@ func SaveToContext(l Logger, base context.Context) context.Context {
 
 func (l Logger) makeCopy() Logger {
 	newLogger := l
-	newLogger.buf = nil
+	newLogger.owns = nil
 	newLogger.tags = map[string]string{}
 	newLogger.meta = nil
+	newLogger.fields = nil
+	if l.fields != nil {
+		newLogger.fields = make([]Field, len(l.fields))
+		copy(newLogger.fields, l.fields)
+	}
 	if l.meta != nil {
 		newLogger.meta = make([]raven.Interface, len(l.meta))
 		for pos, i := range l.meta {
@@ -219,27 +175,111 @@ func (l Logger) AddMeta(meta ...raven.Interface) Logger {
 // Close signifies that a Logger will no longer be used, and the resources allocated to it can be freed.
 // Once the Close method is called, you should not write any more logs using that Logger. Create a new one
 // instead.
+//
+// What Close frees depends on where the Logger came from. Closing a Logger returned by New closes its
+// output (as described there) and its Sentry client, after closing every Logger derived from it with
+// WithSink, WithSentry, or WithReporter. Closing a Logger returned by one of those closes only what
+// that call added, once the Loggers derived from it are done with it. Closing any other Logger, like
+// one returned by AddTags, WithLevel, or WithOutput, only writes out anything held back by sampling or
+// collapsing, and flushes buffered output, so it's harmless to the Loggers it shares its output with.
+// Either way, closing a Logger twice does nothing more than closing it once.
 func (l Logger) Close() {
-	l.sentry.Close()
-	if closer, ok := l.out.(io.Closer); ok {
-		closer.Close()
+	if l.sampler != nil {
+		l.sampler.flush(l)
+	}
+	if l.collapser != nil {
+		l.collapser.flush()
+	}
+	l.core.flush()
+	if l.owns != nil {
+		l.owns.close()
 	}
 }
 
-// GetLevel returns the Level assigned to the Logger.
+// sink returns the Sink l writes to, or nil if it doesn't write anywhere.
+func (l Logger) sink() Sink {
+	if l.core == nil {
+		return nil
+	}
+	return l.core.sink
+}
+
+// reporter returns the Reporter l sends events to, or nil if it doesn't send them anywhere.
+func (l Logger) reporter() Reporter {
+	if l.core == nil {
+		return nil
+	}
+	return l.core.reporter
+}
+
+// GetLevel returns the Level assigned to the Logger. If the Logger is following a LevelVar, this is
+// the LevelVar's current Level.
 func (l Logger) GetLevel() Level {
+	if l.levelVar != nil {
+		return l.levelVar.Level()
+	}
 	return l.level
 }
 
-// WithLevel returns a Logger identical to l, but with the passed Level assigned.
+// WithLevel returns a Logger identical to l, but with the passed Level assigned. The returned Logger
+// stops following any LevelVar l was following.
 func (l Logger) WithLevel(lvl Level) Logger {
 	l.level = lvl
+	l.levelVar = nil
+	l.owns = nil
+	return l
+}
+
+// WithLevelVar returns a Logger identical to l, but that takes its Level from v. Every Logger derived
+// from the returned Logger (using AddTags, WithOutput, etc.) follows v too, so calling v.Set changes the
+// Level of all of them at once.
+func (l Logger) WithLevelVar(v *LevelVar) Logger {
+	l.levelVar = v
+	l.owns = nil
 	return l
 }
 
-// WithOutput returns a Logger identical to l, but with the log output going to `out` instead.
+// enabled returns true if a statement logged at lvl should be written by l. It must be called
+// directly from the method the statement was logged with, so it can find the statement's call site
+// when l has level overrides.
+func (l Logger) enabled(lvl Level) bool {
+	if l.overrides != nil {
+		return l.enabledAt(lvl, callSite(l.calldepth+2))
+	}
+	return l.GetLevel().Enabled(lvl)
+}
+
+// enabledAt is enabled, for statements whose call site is already known. A pc of 0 means the call
+// site is unknown, so no level override applies.
+func (l Logger) enabledAt(lvl Level, pc uintptr) bool {
+	if l.overrides != nil && pc != 0 {
+		if override, ok := l.overrides.forCaller(pc); ok {
+			return override.Enabled(lvl)
+		}
+	}
+	return l.GetLevel().Enabled(lvl)
+}
+
+// WithOutput returns a Logger identical to l, but with the log output going to `out` instead. out
+// isn't closed when the returned Logger is closed; that's left to whoever opened it. Since there's
+// nothing else for it to close, the returned Logger doesn't need closing either, which makes it
+// suitable for short-lived uses, like collecting the entries of a single request, that are simply
+// dropped when they're done.
 func (l Logger) WithOutput(out io.Writer) Logger {
-	l.out = out
+	var sink Sink
+	if out != nil {
+		sink = WriterSink(out)
+	}
+	l.core = l.core.withOutput(sink)
+	l.owns = nil
+	return l
+}
+
+// WithSink returns a Logger identical to l, but writing its entries to s instead. If s is an
+// io.Closer, it's closed when the returned Logger is closed.
+func (l Logger) WithSink(s Sink) Logger {
+	l.core = l.core.withSink(s)
+	l.owns = l.core
 	return l
 }
 
@@ -247,23 +287,30 @@ func (l Logger) WithOutput(out io.Writer) Logger {
 // how many calls up the stack the Logger should look when deciding what file/line combo created the log
 // statement. This defaults to 0, which is accurate if you're just calling the Logger directly. For every
 // level of indirection, add 1. WithCallDepth returns a copy of l, but with the call depth set to `depth`.
+// Marking the helpers with Helper, or their packages with SkipPackages, is usually less brittle.
 func (l Logger) WithCallDepth(depth int) Logger {
 	l.calldepth = depth
+	l.owns = nil
 	return l
 }
 
 // WithSentry returns a copy of l, but with the DSN and tags that will be used to send errors to Sentry
-// overwritten by dsn and tags.
+// overwritten by dsn and tags. The Sentry client is closed when the returned Logger is closed; l's
+// client, if it has one, is left alone.
 func (l Logger) WithSentry(dsn string, tags map[string]string) (Logger, error) {
 	sentryClient, err := raven.NewClient(dsn, tags)
 	if err != nil {
 		return l, err
 	}
-	if l.sentry != nil {
-		l.sentry.Close()
-	}
-	l.sentry = sentryClient
-	return l, nil
+	return l.WithReporter(SentryReporter(sentryClient)), nil
+}
+
+// WithReporter returns a copy of l that sends the events it would send to Sentry to r instead. r is
+// closed when the returned Logger is closed. A nil Reporter turns reporting off.
+func (l Logger) WithReporter(r Reporter) Logger {
+	l.core = l.core.withReporter(r)
+	l.owns = l.core
+	return l
 }
 
 // WithPackagePrefixes returns a copy of l with the package prefixes that will be used to determine
@@ -272,28 +319,62 @@ func (l Logger) WithSentry(dsn string, tags map[string]string) (Logger, error) {
 // as opposed to being from a third party library or from the standard library.
 func (l Logger) WithPackagePrefixes(prefixes []string) Logger {
 	l.packagePrefixes = prefixes
+	l.owns = nil
 	return l
 }
 
 // WithRelease sets the release of the application (usually a git SHA1) that
 // recorded the log. This is only used to tag the logs sent to Sentry, so we
-// know which releases produced the errors.
+// know which releases produced the errors. Only the returned Logger, and those
+// derived from it, tag their logs with release.
 func (l Logger) WithRelease(release string) Logger {
-	if l.sentry == nil {
-		return l
-	}
-	l.sentry.SetRelease(release)
+	l.release = release
+	l.owns = nil
 	return l
 }
 
+// Tracef writes a log entry with the Level of TraceLvl, interpolating the format
+// string with the arguments passed. See fmt.Sprintf for information on variable
+// placeholders in the format string.
+func (l Logger) Tracef(format string, msg ...interface{}) {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(TraceLvl) {
+		return
+	}
+	if !l.sampled(TraceLvl, format, nil) {
+		return
+	}
+	l.logf(format, TraceLvl, msg...)
+}
+
+// Trace writes a log entry with the Level of TraceLvl, joining each argument passed
+// with a space.
+func (l Logger) Trace(msg ...interface{}) {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(TraceLvl) {
+		return
+	}
+	if !l.sampled(TraceLvl, "", msg) {
+		return
+	}
+	l.log(TraceLvl, msg...)
+}
+
 // Debugf writes a log entry with the Level of DebugLvl, interpolating the format
 // string with the arguments passed. See fmt.Sprintf for information on variable
 // placeholders in the format string.
 func (l Logger) Debugf(format string, msg ...interface{}) {
-	if l.out == nil {
+	if l.sink() == nil {
 		return
 	}
-	if !l.level.includes(DebugLvl) {
+	if !l.enabled(DebugLvl) {
+		return
+	}
+	if !l.sampled(DebugLvl, format, nil) {
 		return
 	}
 	l.logf(format, DebugLvl, msg...)
@@ -302,10 +383,13 @@ func (l Logger) Debugf(format string, msg ...interface{}) {
 // Debug writes a log entry with the Level of DebugLvl, joining each argument passed
 // with a space.
 func (l Logger) Debug(msg ...interface{}) {
-	if l.out == nil {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(DebugLvl) {
 		return
 	}
-	if !l.level.includes(DebugLvl) {
+	if !l.sampled(DebugLvl, "", msg) {
 		return
 	}
 	l.log(DebugLvl, msg...)
@@ -315,10 +399,13 @@ func (l Logger) Debug(msg ...interface{}) {
 // string with the arguments passed. See fmt.Sprintf for information on variable
 // placeholders in the format string.
 func (l Logger) Infof(format string, msg ...interface{}) {
-	if l.out == nil {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(InfoLvl) {
 		return
 	}
-	if !l.level.includes(InfoLvl) {
+	if !l.sampled(InfoLvl, format, nil) {
 		return
 	}
 	l.logf(format, InfoLvl, msg...)
@@ -327,10 +414,13 @@ func (l Logger) Infof(format string, msg ...interface{}) {
 // Info writes a log entry with the Level of InfoLvl, joining each argument passed
 // with a space.
 func (l Logger) Info(msg ...interface{}) {
-	if l.out == nil {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(InfoLvl) {
 		return
 	}
-	if !l.level.includes(InfoLvl) {
+	if !l.sampled(InfoLvl, "", msg) {
 		return
 	}
 	l.log(InfoLvl, msg...)
@@ -343,14 +433,18 @@ func (l Logger) Info(msg ...interface{}) {
 // Any message logged with Warnf will automatically be sent to Sentry, if Sentry
 // has been configured.
 func (l Logger) Warnf(format string, msg ...interface{}) {
-	if l.out == nil {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(WarnLvl) {
 		return
 	}
-	if !l.level.includes(WarnLvl) {
+	if !l.sampled(WarnLvl, format, nil) {
 		return
 	}
-	l.logf(format, WarnLvl, msg...)
-	l.toSentry(format, msg, WarnLvl)
+	if l.logf(format, WarnLvl, msg...) {
+		l.toSentry(format, msg, WarnLvl)
+	}
 }
 
 // Warn writes a log entry with the Level of WarnLvl, joining each argument passed
@@ -359,14 +453,18 @@ func (l Logger) Warnf(format string, msg ...interface{}) {
 // Any message logged with Warn will automatically be sent to Sentry, if Sentry
 // has been configured.
 func (l Logger) Warn(msg ...interface{}) {
-	if l.out == nil {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(WarnLvl) {
 		return
 	}
-	if !l.level.includes(WarnLvl) {
+	if !l.sampled(WarnLvl, "", msg) {
 		return
 	}
-	l.log(WarnLvl, msg...)
-	l.toSentry(fmt.Sprintln(msg...), []interface{}{}, WarnLvl)
+	if l.log(WarnLvl, msg...) && l.reporter() != nil {
+		l.toSentry(fmt.Sprintln(msg...), []interface{}{}, WarnLvl)
+	}
 }
 
 // Errorf writes a log entry with the Level of ErrorLvl, interpolating the format
@@ -376,14 +474,18 @@ func (l Logger) Warn(msg ...interface{}) {
 // Any message logged with Errorf will automatically be sent to Sentry, if Sentry
 // has been configured.
 func (l Logger) Errorf(format string, msg ...interface{}) {
-	if l.out == nil {
+	if l.sink() == nil {
 		return
 	}
-	if !l.level.includes(ErrorLvl) {
+	if !l.enabled(ErrorLvl) {
 		return
 	}
-	l.logf(format, ErrorLvl, msg...)
-	l.toSentry(format, msg, ErrorLvl)
+	if !l.sampled(ErrorLvl, format, nil) {
+		return
+	}
+	if l.logf(format, ErrorLvl, msg...) {
+		l.toSentry(format, msg, ErrorLvl)
+	}
 }
 
 // Error writes a log entry with the Level of ErrorLvl, joining each argument passed
@@ -392,28 +494,165 @@ func (l Logger) Errorf(format string, msg ...interface{}) {
 // Any message logged with Error will automatically be sent to Sentry, if Sentry
 // has been configured.
 func (l Logger) Error(msg ...interface{}) {
-	if l.out == nil {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(ErrorLvl) {
 		return
 	}
-	if !l.level.includes(ErrorLvl) {
+	if !l.sampled(ErrorLvl, "", msg) {
 		return
 	}
-	l.log(ErrorLvl, msg...)
-	l.toSentry(fmt.Sprintln(msg...), []interface{}{}, ErrorLvl)
+	if l.log(ErrorLvl, msg...) && l.reporter() != nil {
+		l.toSentry(fmt.Sprintln(msg...), []interface{}{}, ErrorLvl)
+	}
 }
 
-func (l Logger) log(lvl Level, msg ...interface{}) {
-	err := l.output(l.calldepth+3, fmt.Sprintln(msg...), lvl)
-	if err != nil {
-		os.Stderr.Write([]byte(time.Now().String() + " " + err.Error()))
+// Logf writes a log entry with the Level of lvl, interpolating the format string
+// with the arguments passed. See fmt.Sprintf for information on variable placeholders
+// in the format string. Logf is meant for Levels registered with RegisterLevel; the
+// predefined Levels have methods of their own.
+//
+// Any message logged with Logf at WarnLvl or above will automatically be sent to
+// Sentry, if Sentry has been configured. Logf never panics or exits, whatever lvl is.
+func (l Logger) Logf(lvl Level, format string, msg ...interface{}) {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(lvl) {
+		return
+	}
+	if !l.sampled(lvl, format, nil) {
+		return
+	}
+	if l.logf(format, lvl, msg...) && lvl >= WarnLvl {
+		l.toSentry(format, msg, lvl)
+	}
+}
+
+// Log writes a log entry with the Level of lvl, joining each argument passed with
+// a space. Log is meant for Levels registered with RegisterLevel; the predefined
+// Levels have methods of their own.
+//
+// Any message logged with Log at WarnLvl or above will automatically be sent to
+// Sentry, if Sentry has been configured. Log never panics or exits, whatever lvl is.
+func (l Logger) Log(lvl Level, msg ...interface{}) {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(lvl) {
+		return
+	}
+	if !l.sampled(lvl, "", msg) {
+		return
+	}
+	if l.log(lvl, msg...) && lvl >= WarnLvl && l.reporter() != nil {
+		l.toSentry(fmt.Sprintln(msg...), []interface{}{}, lvl)
+	}
+}
+
+// Panicf writes a log entry with the Level of PanicLvl, interpolating the format
+// string with the arguments passed, and then panics with the resulting message.
+// See fmt.Sprintf for information on variable placeholders in the format string.
+//
+// Any message logged with Panicf will automatically be sent to Sentry, if Sentry
+// has been configured. Panicf panics even if the Logger's Level excludes PanicLvl.
+func (l Logger) Panicf(format string, msg ...interface{}) {
+	if l.sink() != nil && l.enabled(PanicLvl) {
+		l.logf(format, PanicLvl, msg...)
+		l.toSentry(format, msg, PanicLvl)
+	}
+	panic(fmt.Sprintf(format, msg...))
+}
+
+// Panic writes a log entry with the Level of PanicLvl, joining each argument passed
+// with a space, and then panics with the resulting message.
+//
+// Any message logged with Panic will automatically be sent to Sentry, if Sentry
+// has been configured. Panic panics even if the Logger's Level excludes PanicLvl.
+func (l Logger) Panic(msg ...interface{}) {
+	if l.sink() != nil && l.enabled(PanicLvl) {
+		l.log(PanicLvl, msg...)
+		if l.reporter() != nil {
+			l.toSentry(fmt.Sprintln(msg...), []interface{}{}, PanicLvl)
+		}
+	}
+	panic(strings.TrimSuffix(fmt.Sprintln(msg...), "\n"))
+}
+
+// Fatalf writes a log entry with the Level of FatalLvl, interpolating the format
+// string with the arguments passed. See fmt.Sprintf for information on variable
+// placeholders in the format string. The Logger is then flushed and closed, and
+// the program exits with a status of 1.
+//
+// Any message logged with Fatalf will automatically be sent to Sentry, if Sentry
+// has been configured, and the event is delivered before the program exits. Fatalf
+// exits even if the Logger's Level excludes FatalLvl.
+func (l Logger) Fatalf(format string, msg ...interface{}) {
+	if l.sink() != nil && l.enabled(FatalLvl) {
+		l.logf(format, FatalLvl, msg...)
+		l.toSentry(format, msg, FatalLvl)
 	}
+	l.Close()
+	exit(1)
+}
+
+// Fatal writes a log entry with the Level of FatalLvl, joining each argument passed
+// with a space. The Logger is then flushed and closed, and the program exits with a
+// status of 1.
+//
+// Any message logged with Fatal will automatically be sent to Sentry, if Sentry
+// has been configured, and the event is delivered before the program exits. Fatal
+// exits even if the Logger's Level excludes FatalLvl.
+func (l Logger) Fatal(msg ...interface{}) {
+	if l.sink() != nil && l.enabled(FatalLvl) {
+		l.log(FatalLvl, msg...)
+		if l.reporter() != nil {
+			l.toSentry(fmt.Sprintln(msg...), []interface{}{}, FatalLvl)
+		}
+	}
+	l.Close()
+	exit(1)
+}
+
+// log writes a statement joining msg, and returns false if it was held back as a repeat by
+// WithCollapsedRepeats, in which case it shouldn't be sent to Sentry either.
+func (l Logger) log(lvl Level, msg ...interface{}) bool {
+	return l.logged(l.output(l.calldepth+3, sprintln(msg), lvl))
+}
+
+// logf is log, for statements with a format string.
+func (l Logger) logf(format string, lvl Level, msg ...interface{}) bool {
+	return l.logged(l.output(l.calldepth+3, sprintf(format, msg), lvl))
 }
 
-func (l Logger) logf(format string, lvl Level, msg ...interface{}) {
-	err := l.output(l.calldepth+3, fmt.Sprintf(format, msg...), lvl)
+func (l Logger) logged(err error) bool {
+	if err == errHeld {
+		return false
+	}
 	if err != nil {
 		os.Stderr.Write([]byte(time.Now().String() + " " + err.Error()))
 	}
+	return true
+}
+
+// sprintln is fmt.Sprintln, minus the allocation when msg is a single string, which is how most
+// messages are logged. output drops the trailing newline either way.
+func sprintln(msg []interface{}) string {
+	if len(msg) == 1 {
+		if s, ok := msg[0].(string); ok && (s == "" || s[len(s)-1] != '\n') {
+			return s
+		}
+	}
+	return fmt.Sprintln(msg...)
+}
+
+// sprintf is fmt.Sprintf, minus the allocation when there is nothing to interpolate.
+func sprintf(format string, msg []interface{}) string {
+	if len(msg) == 0 && strings.IndexByte(format, '%') < 0 {
+		return format
+	}
+	return fmt.Sprintf(format, msg...)
 }
 
 // Cheap integer to fixed-width decimal ASCII.  Give a negative width to avoid zero-padding.
@@ -441,61 +680,94 @@ func itoa(buf *[]byte, i int, wid int) {
 // Prepend our log header to the buffer.
 //
 // Heavily modified form of https://github.com/golang/go/blob/883bc6ed0ea815293fe6309d66f967ea60630e87/src/log/log.go#L80
-func formatHeader(buf *[]byte, now time.Time, file string, line int, level Level) {
-	year, month, day := now.Date()
-	itoa(buf, year, 4)
-	*buf = append(*buf, '-')
-	itoa(buf, int(month), 2)
-	*buf = append(*buf, '-')
-	itoa(buf, day, 2)
-	*buf = append(*buf, 'T')
-	hour, minute, second := now.Clock()
-	itoa(buf, hour, 2)
-	*buf = append(*buf, ':')
-	itoa(buf, minute, 2)
-	*buf = append(*buf, ':')
-	itoa(buf, second, 2)
-
-	*buf = append(*buf, " ["+string(level)+"] "...)
-
-	*buf = append(*buf, file...)
-	*buf = append(*buf, ':')
-	itoa(buf, line, -1)
-	*buf = append(*buf, ": "...)
-}
-
-// Actually write to l.out after gathering caller information
+func formatHeader(buf *[]byte, now time.Time, file string, line int, level Level, flags Flags) {
+	if flags&TimeNone == 0 {
+		appendTime(buf, now, flags)
+		*buf = append(*buf, ' ')
+	}
+	*buf = append(*buf, '[')
+	*buf = append(*buf, level.String()...)
+	*buf = append(*buf, "] "...)
+
+	if file != "" {
+		*buf = append(*buf, file...)
+		*buf = append(*buf, ':')
+		itoa(buf, line, -1)
+		*buf = append(*buf, ": "...)
+	}
+}
+
+// Actually write to l's Sink after gathering caller information
 //
 // Heavily modified version of https://github.com/golang/go/blob/883bc6ed0ea815293fe6309d66f967ea60630e87/src/log/log.go#L130
+//
+// It returns errHeld if the entry was held back as a repeat by WithCollapsedRepeats.
 func (l Logger) output(calldepth int, s string, lvl Level) error {
-	now := time.Now()
-	_, file, line, ok := runtime.Caller(calldepth)
-	if !ok {
-		file = "???"
-		line = 0
+	var pc uintptr
+	if l.flags&CallerNone == 0 || l.collapser != nil {
+		pc = callSite(calldepth)
+	}
+	return l.write(l.now(), pc, s, lvl)
+}
+
+// write does the work of output for entries whose time and call site are already known, like those
+// handed over by other logging packages. A pc of 0 means the call site is unknown.
+func (l Logger) write(now time.Time, pc uintptr, s string, lvl Level) error {
+	b := getEntryBuffer()
+	defer putEntryBuffer(b)
+	e := &b.e
+	*e = Entry{Time: now, Level: lvl, PC: pc, Fields: l.fields, Flags: l.flags, Escaping: l.escaping, Tags: l.tags}
+	if l.flags&CallerNone == 0 {
+		if pc != 0 {
+			c := callerAt(pc)
+			e.File, e.Line = formatCaller(c, l.flags), c.line
+		} else {
+			e.File = "???"
+		}
 	}
-	l.buf = l.buf[:0]
-	formatHeader(&l.buf, now, file, line, lvl)
-	l.buf = append(l.buf, s...)
-	if len(s) > 0 && s[len(s)-1] != '\n' {
-		l.buf = append(l.buf, '\n')
+	s = l.redactor.String(s)
+	if l.escaping != EscapeNone && needsEscaping(s) {
+		s = string(appendEscaped(nil, s, l.escaping))
+	} else if len(s) > 0 && s[len(s)-1] == '\n' {
+		s = s[:len(s)-1]
+	}
+	e.Message = s
+	if l.redactor != nil {
+		e.Fields = l.redactor.fields(e.Fields)
+		e.Tags = l.redactor.fieldMap(e.Tags)
+	}
+	formatter := l.getFormatter()
+	b.buf = formatter.Format(b.buf, e)
+	sink := l.sink()
+	if sink == nil {
+		return nil
+	}
+	l.core.lock.Lock()
+	defer l.core.lock.Unlock()
+	if l.collapser != nil {
+		held, err := l.collapser.hold(l.core, formatter, l.clock, e)
+		if held {
+			return errHeld
+		}
+		if werr := sink.WriteEntry(e, b.buf); werr != nil {
+			err = werr
+		}
+		return err
 	}
-	l.flock.Lock()
-	defer l.flock.Unlock()
-	_, err := l.out.Write(l.buf)
-	return err
+	return sink.WriteEntry(e, b.buf)
 }
 
 // Send output to Sentry
 func (l Logger) toSentry(format string, args []interface{}, lvl Level) {
-	if l.sentry == nil {
+	reporter := l.reporter()
+	if reporter == nil {
 		return
 	}
 	msg := raven.Message{
 		Message: format,
 		Params:  args,
 	}
-	stack := raven.NewStacktrace(l.calldepth+2, 2, l.packagePrefixes)
+	stack := raven.NewStacktrace(callerSkip(l.calldepth+2), 2, l.packagePrefixes)
 	interfaces := []raven.Interface{&msg, stack}
 	for _, arg := range args {
 		if i, ok := l.asSentryInterface(arg); ok {
@@ -505,10 +777,35 @@ func (l Logger) toSentry(format string, args []interface{}, lvl Level) {
 	if l.meta != nil && len(l.meta) > 0 {
 		interfaces = append(interfaces, l.meta...)
 	}
-	packet := raven.NewPacket(fmt.Sprintf(format, args...), interfaces...)
+	message := fmt.Sprintf(format, args...)
+	tags := l.tags
+	if l.redactor != nil {
+		for pos, i := range interfaces {
+			interfaces[pos] = l.redactor.sentryInterface(i)
+		}
+		message = l.redactor.String(message)
+		tags = l.redactor.fieldMap(tags)
+	}
+	packet := raven.NewPacket(message, interfaces...)
 	packet.Level = lvl.asSentryLevel()
-	_, ch := l.sentry.Capture(packet, l.tags)
-	err := <-ch
+	if len(l.fields) > 0 {
+		fields := l.fields
+		if l.redactor != nil {
+			fields = l.redactor.fields(fields)
+		}
+		packet.Extra = make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			if err, ok := f.Value().(error); ok {
+				packet.Extra[f.Key] = err.Error()
+				continue
+			}
+			packet.Extra[f.Key] = f.Value()
+		}
+	}
+	if l.release != "" {
+		packet.Release = l.release
+	}
+	err := reporter.Report(packet, tags)
 	if err != nil {
 		l.output(1, err.Error(), ErrorLvl)
 	}
@@ -517,7 +814,7 @@ func (l Logger) toSentry(format string, args []interface{}, lvl Level) {
 func (l Logger) asSentryInterface(arg interface{}) (raven.Interface, bool) {
 	switch arg.(type) {
 	case error:
-		stack := raven.NewStacktrace(l.calldepth+3, 2, l.packagePrefixes)
+		stack := raven.NewStacktrace(callerSkip(l.calldepth+3), 2, l.packagePrefixes)
 		exception := raven.NewException(arg.(error), stack)
 		return exception, true
 	case *http.Request:
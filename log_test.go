@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"os"
@@ -34,7 +35,7 @@ func getFilePath() string {
 	return path.Join(name...)
 }
 
-func TestLevelIncludes(t *testing.T) {
+func TestLevelEnabled(t *testing.T) {
 	type levelTest struct {
 		// logLevel is the level configured on the log
 		// stmtLevel is the level the statement is logged with
@@ -43,6 +44,9 @@ func TestLevelIncludes(t *testing.T) {
 		includes            bool
 	}
 	levelTests := []levelTest{
+		{logLevel: TraceLvl, stmtLevel: TraceLvl, includes: true},
+		{logLevel: TraceLvl, stmtLevel: DebugLvl, includes: true},
+		{logLevel: DebugLvl, stmtLevel: TraceLvl, includes: false},
 		{logLevel: DebugLvl, stmtLevel: DebugLvl, includes: true},
 		{logLevel: DebugLvl, stmtLevel: InfoLvl, includes: true},
 		{logLevel: DebugLvl, stmtLevel: WarnLvl, includes: true},
@@ -59,21 +63,32 @@ func TestLevelIncludes(t *testing.T) {
 		{logLevel: ErrorLvl, stmtLevel: InfoLvl, includes: false},
 		{logLevel: ErrorLvl, stmtLevel: WarnLvl, includes: false},
 		{logLevel: ErrorLvl, stmtLevel: ErrorLvl, includes: true},
+		{logLevel: ErrorLvl, stmtLevel: PanicLvl, includes: true},
+		{logLevel: ErrorLvl, stmtLevel: FatalLvl, includes: true},
+		{logLevel: PanicLvl, stmtLevel: ErrorLvl, includes: false},
+		{logLevel: PanicLvl, stmtLevel: PanicLvl, includes: true},
+		{logLevel: PanicLvl, stmtLevel: FatalLvl, includes: true},
+		{logLevel: FatalLvl, stmtLevel: ErrorLvl, includes: false},
+		{logLevel: FatalLvl, stmtLevel: PanicLvl, includes: false},
+		{logLevel: FatalLvl, stmtLevel: FatalLvl, includes: true},
 	}
 	for _, test := range levelTests {
-		includes := test.logLevel.includes(test.stmtLevel)
+		includes := test.logLevel.Enabled(test.stmtLevel)
 		if includes != test.includes {
-			t.Errorf("Expected %s.Includes(%s) to be %t, got %t", test.logLevel, test.stmtLevel, test.includes, includes)
+			t.Errorf("Expected %s.Enabled(%s) to be %t, got %t", test.logLevel, test.stmtLevel, test.includes, includes)
 		}
 	}
 }
 
 func TestLevelAsSentryLevel(t *testing.T) {
 	conversionTests := map[Level]raven.Severity{
+		TraceLvl: raven.DEBUG,
 		DebugLvl: raven.DEBUG,
 		InfoLvl:  raven.INFO,
 		WarnLvl:  raven.WARNING,
 		ErrorLvl: raven.ERROR,
+		PanicLvl: raven.FATAL,
+		FatalLvl: raven.FATAL,
 	}
 	for lvl, sev := range conversionTests {
 		result := lvl.asSentryLevel()
@@ -148,7 +163,10 @@ func TestFormatHeader(t *testing.T) {
 		file  string
 		line  int
 		level Level
+		flags Flags
 	}
+	est := time.FixedZone("EST", -5*60*60)
+	ist := time.FixedZone("IST", 5*60*60+30*60)
 	headers := map[string]header{
 		"2015-07-02T13:28:42 [WARN] /my/test/file.go:145: ": {
 			now:   time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
@@ -156,10 +174,65 @@ func TestFormatHeader(t *testing.T) {
 			line:  145,
 			level: WarnLvl,
 		},
+		"2015-07-02T08:28:42 [INFO] file.go:1: ": {
+			now:   time.Date(2015, time.July, 2, 8, 28, 42, 107000000, est),
+			file:  "file.go",
+			line:  1,
+			level: InfoLvl,
+		},
+		"2015-07-02T13:28:42.107Z [INFO] file.go:1: ": {
+			now:   time.Date(2015, time.July, 2, 8, 28, 42, 107654321, est),
+			file:  "file.go",
+			line:  1,
+			level: InfoLvl,
+			flags: TimeUTC | TimeMillis | TimeZone,
+		},
+		"2015-07-02T08:28:42.107654-05:00 [INFO] file.go:1: ": {
+			now:   time.Date(2015, time.July, 2, 8, 28, 42, 107654321, est),
+			file:  "file.go",
+			line:  1,
+			level: InfoLvl,
+			flags: TimeMicros | TimeZone,
+		},
+		"2015-07-02T18:58:42.107654321+05:30 [INFO] file.go:1: ": {
+			now:   time.Date(2015, time.July, 2, 18, 58, 42, 107654321, ist),
+			file:  "file.go",
+			line:  1,
+			level: InfoLvl,
+			flags: TimeMillis | TimeNanos | TimeZone,
+		},
+		"2015-07-02T13:28:42.000 [DEBUG] file.go:1: ": {
+			now:   time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:  "file.go",
+			line:  1,
+			level: DebugLvl,
+			flags: TimeMillis,
+		},
+		"1435843722 [ERROR] file.go:2: ": {
+			now:   time.Date(2015, time.July, 2, 8, 28, 42, 107654321, est),
+			file:  "file.go",
+			line:  2,
+			level: ErrorLvl,
+			flags: TimeUnix,
+		},
+		"1435843722.107 [ERROR] file.go:2: ": {
+			now:   time.Date(2015, time.July, 2, 8, 28, 42, 107654321, est),
+			file:  "file.go",
+			line:  2,
+			level: ErrorLvl,
+			flags: TimeUnix | TimeMillis | TimeZone,
+		},
+		"[WARN] file.go:3: ": {
+			now:   time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:  "file.go",
+			line:  3,
+			level: WarnLvl,
+			flags: TimeNone | TimeNanos,
+		},
 	}
 	for out, in := range headers {
 		var buf []byte
-		formatHeader(&buf, in.now, in.file, in.line, in.level)
+		formatHeader(&buf, in.now, in.file, in.line, in.level, in.flags)
 		if string(buf) != out {
 			t.Errorf("Expected output to be '%s', got '%s' from %+v\n", out, string(buf), in)
 		}
@@ -172,18 +245,19 @@ func TestOutput(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Unexpected error: %+v\n", err)
 	}
+	log = log.WithClock(ClockFunc(func() time.Time {
+		return time.Date(2015, 7, 2, 13, 28, 42, 0, time.Local)
+	}))
 	err = log.output(0, "My test output", InfoLvl)
 	if err != nil {
 		t.Errorf("Unexpected error: %+v\n", err)
 	}
-	year, month, day := time.Now().Date()
-	hour, minute, second := time.Now().Clock()
 	file := getFilePath()
-	line := 472
+	line := 708
 	if testing.Coverage() > 0 {
-		line = 577
+		line = 813
 	}
-	expected := fmt.Sprintf("%04d-%02d-%02dT%02d:%02d:%02d [%s] %s:%d: %s\n", year, month, day, hour, minute, second, InfoLvl, file, line, "My test output")
+	expected := fmt.Sprintf("2015-07-02T13:28:42 [%s] %s:%d: %s\n", InfoLvl, file, line, "My test output")
 	if buf.String() != expected {
 		t.Errorf("Expected output to be '%s', got '%s' instead\n", expected, buf.String())
 	}
@@ -226,9 +300,9 @@ func TestHelpers(t *testing.T) {
 	year, month, day := time.Now().Date()
 	hour, minute, second := time.Now().Clock()
 	file := getFilePath()
-	line := 405
+	line := 620
 	if testing.Coverage() > 0 {
-		line = 500
+		line = 715
 	}
 	for pos, test := range levelTests {
 		buf.Reset()
@@ -252,9 +326,9 @@ func TestHelpers(t *testing.T) {
 			t.Errorf("Unexpected level: %s\n", test.stmtLevel)
 		}
 		f("Test number", pos)
-		line = 406
+		line = 621
 		if testing.Coverage() > 0 {
-			line = 501
+			line = 716
 		}
 		var expectation string
 		if test.includes {
@@ -268,9 +342,9 @@ func TestHelpers(t *testing.T) {
 
 		buf.Reset()
 		ff("Test number %d", pos)
-		line = 413
+		line = 626
 		if testing.Coverage() > 0 {
-			line = 510
+			line = 723
 		}
 		if test.includes {
 			expectation = fmt.Sprintf("%04d-%02d-%02dT%02d:%02d:%02d [%s] %s:%d: %s %d\n", year, month, day, hour, minute, second, test.stmtLevel, file, line, "Test number", pos)
@@ -282,3 +356,83 @@ func TestHelpers(t *testing.T) {
 		}
 	}
 }
+
+type closeRecorder struct {
+	*bufio.Writer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestFatal(t *testing.T) {
+	defer func() { exit = os.Exit }()
+	var code int
+	exit = func(c int) { code = c }
+
+	var buf bytes.Buffer
+	out := &closeRecorder{Writer: bufio.NewWriter(&buf)}
+	log, err := New(ErrorLvl, out, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Fatalf("Test number %d", 1)
+	if code != 1 {
+		t.Errorf("Expected exit code 1, got %d instead\n", code)
+	}
+	if !out.closed {
+		t.Error("Expected output to be closed before exiting")
+	}
+	if !strings.Contains(buf.String(), "[FATAL]") || !strings.HasSuffix(buf.String(), "Test number 1\n") {
+		t.Errorf("Expected buffered output to be flushed before exiting, got `%s`\n", buf.String())
+	}
+}
+
+func TestPanic(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(FatalLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defer func() {
+		r := recover()
+		if r != "Test number 2" {
+			t.Errorf("Expected to panic with `Test number 2`, got %#v instead\n", r)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Expected PanicLvl to be excluded from a FatalLvl Logger, got `%s`\n", buf.String())
+		}
+	}()
+	log.Panic("Test number", 2)
+}
+
+func TestSprint(t *testing.T) {
+	lines := [][]interface{}{
+		{"static message"},
+		{""},
+		{"trailing newline\n"},
+		{"two", "strings"},
+		{"number", 42},
+		{42},
+		{},
+	}
+	for _, msg := range lines {
+		if expected, got := strings.TrimSuffix(fmt.Sprintln(msg...), "\n"), strings.TrimSuffix(sprintln(msg), "\n"); got != expected {
+			t.Errorf("Expected sprintln(%#v) to be %q, got %q instead\n", msg, expected, got)
+		}
+	}
+	formats := map[string][]interface{}{
+		"static message": nil,
+		"100%% done":     nil,
+		"%d%% done":      {100},
+		"hello, %s":      {"world"},
+		"extra":          {"argument"},
+	}
+	for format, msg := range formats {
+		if expected, got := fmt.Sprintf(format, msg...), sprintf(format, msg); got != expected {
+			t.Errorf("Expected sprintf(%q) to be %q, got %q instead\n", format, expected, got)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Package logrlogging connects github.com/go-logr/logr to github.com/DramaFever/go-logging, so that
+// libraries that log through logr, like client-go and controller-runtime, are written by a Logger.
+package logrlogging
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/go-logr/logr"
+
+	"github.com/DramaFever/go-logging"
+)
+
+var skipOnce sync.Once
+
+// NewLogSink returns a logr.LogSink that writes to l. It's usually wrapped in a logr.Logger right
+// away:
+//
+//	ctrl.SetLogger(logr.New(logrlogging.NewLogSink(log)))
+//
+// logr's verbosity levels map onto l's: V(0) is InfoLvl, V(1) is DebugLvl, and V(n) is l.V(n-1),
+// so l's Level and level overrides decide which are written. Errors are logged at ErrorLvl with
+// Errorf, so they're sent to Sentry with the error as the exception.
+//
+// The keys and values passed to WithValues, Info, and Error become fields. Names added with WithName
+// are joined with "/" and added as the field "logger". Entries are attributed to the code that called
+// logr, rather than to logr itself.
+func NewLogSink(l logging.Logger) logr.LogSink {
+	skipOnce.Do(func() {
+		// Skipping this package too, rather than calling logging.Helper in every method, keeps the
+		// methods cheap when logr asks about verbosity levels that are turned off.
+		logging.SkipPackages("github.com/go-logr/logr", reflect.TypeOf(logSink{}).PkgPath())
+	})
+	return &logSink{l: l}
+}
+
+type logSink struct {
+	l    logging.Logger
+	name string
+}
+
+func (s *logSink) Init(info logr.RuntimeInfo) {}
+
+// level returns the Level of logr's verbosity level v.
+func level(v int) logging.Level {
+	if v <= 0 {
+		return logging.InfoLvl
+	}
+	return logging.DebugLvl - logging.Level(v-1)
+}
+
+func (s *logSink) Enabled(v int) bool {
+	// V counts verbosity levels down from DebugLvl, so it can be asked about InfoLvl too.
+	return s.l.V(int(logging.DebugLvl - level(v))).Enabled()
+}
+
+func (s *logSink) Info(v int, msg string, keysAndValues ...interface{}) {
+	s.with(keysAndValues).Log(level(v), msg)
+}
+
+func (s *logSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	l := s.with(keysAndValues)
+	if err == nil {
+		l.Error(msg)
+		return
+	}
+	l.Errorf("%s: %v", msg, err)
+}
+
+func (s *logSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &logSink{l: s.l.AddFields(fields(keysAndValues)...), name: s.name}
+}
+
+func (s *logSink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "/" + name
+	}
+	return &logSink{l: s.l, name: name}
+}
+
+// with returns s's Logger with its name and keysAndValues added as fields.
+func (s *logSink) with(keysAndValues []interface{}) logging.Logger {
+	f := fields(keysAndValues)
+	if s.name != "" {
+		f = append([]logging.Field{logging.String("logger", s.name)}, f...)
+	}
+	if len(f) == 0 {
+		return s.l
+	}
+	return s.l.AddFields(f...)
+}
+
+// fields turns logr's alternating keys and values into fields. Keys that aren't strings are formatted
+// with fmt, and a key without a value gets the value "(MISSING)".
+func fields(keysAndValues []interface{}) []logging.Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+	fields := make([]logging.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		var val interface{} = "(MISSING)"
+		if i+1 < len(keysAndValues) {
+			val = keysAndValues[i+1]
+		}
+		fields = append(fields, logging.Any(key, val))
+	}
+	return fields
+}
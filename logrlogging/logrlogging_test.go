@@ -0,0 +1,103 @@
+// The tests are in a package of their own, since NewLogSink skips every function in logrlogging when
+// deciding where entries were logged from, which would include them.
+package logrlogging_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/getsentry/raven-go"
+	"github.com/go-logr/logr"
+
+	"github.com/DramaFever/go-logging"
+	"github.com/DramaFever/go-logging/logrlogging"
+)
+
+type recordingReporter struct {
+	sync.Mutex
+	packets []*raven.Packet
+}
+
+func (r *recordingReporter) Report(packet *raven.Packet, tags map[string]string) error {
+	r.Lock()
+	defer r.Unlock()
+	r.packets = append(r.packets, packet)
+	return nil
+}
+
+func (r *recordingReporter) Close() error {
+	return nil
+}
+
+func TestLogSink(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := logging.New(logging.DebugLvl-1, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	reporter := &recordingReporter{}
+	log := logr.New(logrlogging.NewLogSink(l.WithFlags(logging.TimeNone | logging.CallerShort).WithReporter(reporter)))
+	log = log.WithName("controller").WithName("deployment").WithValues("namespace", "web")
+
+	_, _, line, _ := runtime.Caller(0)
+	log.Info("reconciling", "replicas", 3)
+	log.V(1).Info("fetched object", "generation", int64(7))
+	log.V(2).Info("cache hit")
+	log.V(3).Info("not written")
+	log.Error(errors.New("conflict"), "update failed", "retry", true, "dangling")
+
+	expected := fmt.Sprintf("[INFO] logrlogging_test.go:%d: reconciling namespace=web logger=controller/deployment replicas=3\n", line+1) +
+		fmt.Sprintf("[DEBUG] logrlogging_test.go:%d: fetched object namespace=web logger=controller/deployment generation=7\n", line+2) +
+		fmt.Sprintf("[TRACE+3] logrlogging_test.go:%d: cache hit namespace=web logger=controller/deployment\n", line+3) +
+		fmt.Sprintf("[ERROR] logrlogging_test.go:%d: update failed: conflict namespace=web logger=controller/deployment retry=true dangling=(MISSING)\n", line+5)
+	if buf.String() != expected {
+		t.Errorf("Expected `%s`, got `%s` instead\n", expected, buf.String())
+	}
+
+	if len(reporter.packets) != 1 {
+		t.Fatalf("Expected 1 packet, got %d instead\n", len(reporter.packets))
+	}
+	packet := reporter.packets[0]
+	if packet.Message != "update failed: conflict" || packet.Level != raven.ERROR {
+		t.Errorf("Expected an ERROR event for the error, got %s `%s` instead\n", packet.Level, packet.Message)
+	}
+	var exception *raven.Exception
+	for _, i := range packet.Interfaces {
+		if e, ok := i.(*raven.Exception); ok {
+			exception = e
+		}
+	}
+	if exception == nil || exception.Value != "conflict" {
+		t.Errorf("Expected the error to be sent as an exception, got %+v instead\n", packet.Interfaces)
+	}
+	if packet.Extra["logger"] != "controller/deployment" || packet.Extra["retry"] != true {
+		t.Errorf("Expected the fields as extra data, got %v instead\n", packet.Extra)
+	}
+}
+
+func TestLogSinkEnabled(t *testing.T) {
+	l, err := logging.New(logging.InfoLvl, &bytes.Buffer{}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log := logr.New(logrlogging.NewLogSink(l))
+	if !log.Enabled() {
+		t.Errorf("Expected V(0) to be enabled at InfoLvl\n")
+	}
+	if log.V(1).Enabled() {
+		t.Errorf("Expected V(1) to be disabled at InfoLvl\n")
+	}
+
+	l, err = l.WithLevelOverrides("logrlogging_test.go=DEBUG")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = logr.New(logrlogging.NewLogSink(l))
+	if !log.V(1).Enabled() {
+		t.Errorf("Expected the level override for the caller to enable V(1)\n")
+	}
+}
@@ -0,0 +1,226 @@
+// Package logtest helps test code that logs with github.com/DramaFever/go-logging. Instead of writing
+// to a file and parsing it, tests give the code under test a Logger that records what it logs, and
+// assert on the records:
+//
+//	func TestRetry(t *testing.T) {
+//		log, rec := logtest.New(logging.DebugLvl)
+//		client := NewClient(log)
+//		client.Get("/flaky")
+//		rec.AssertLogged(t, logging.WarnLvl, "retrying")
+//	}
+package logtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/raven-go"
+
+	"github.com/DramaFever/go-logging"
+)
+
+// Record is a log entry captured by a Recorder. File is the caller as the Logger's caller Flags say
+// to write it.
+type Record struct {
+	Time    time.Time
+	Level   logging.Level
+	Message string
+	Fields  []logging.Field
+	File    string
+	Line    int
+	Tags    map[string]string
+}
+
+// Field returns the value of the field named key, and whether the Record has it. If a field was added
+// more than once, the last value wins.
+func (r Record) Field(key string) (interface{}, bool) {
+	for i := len(r.Fields) - 1; i >= 0; i-- {
+		if r.Fields[i].Key == key {
+			return r.Fields[i].Value(), true
+		}
+	}
+	return nil, false
+}
+
+// Recorder is a logging.Sink that keeps every entry written to it as a Record. It's safe for
+// concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+// NewRecorder returns an empty Recorder. Use it with Logger.WithSink, or use New to get a Logger that
+// writes to one.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// New returns a Logger set to lvl that writes to a new Recorder, and the Recorder. The Logger doesn't
+// report to Sentry; use WithReporter and a Reporter to test what's reported.
+func New(lvl logging.Level) (logging.Logger, *Recorder) {
+	rec := NewRecorder()
+	l, _ := logging.New(lvl, nil, "", nil)
+	return l.WithSink(rec), rec
+}
+
+// WriteEntry implements logging.Sink.
+func (r *Recorder) WriteEntry(e *logging.Entry, line []byte) error {
+	record := Record{
+		Time:    e.Time,
+		Level:   e.Level,
+		Message: e.Message,
+		File:    e.File,
+		Line:    e.Line,
+	}
+	if len(e.Fields) > 0 {
+		record.Fields = append([]logging.Field(nil), e.Fields...)
+	}
+	if len(e.Tags) > 0 {
+		record.Tags = make(map[string]string, len(e.Tags))
+		for k, v := range e.Tags {
+			record.Tags[k] = v
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record)
+	return nil
+}
+
+// Records returns a copy of the Records captured so far, oldest first.
+func (r *Recorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Record(nil), r.records...)
+}
+
+// Reset discards the Records captured so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+}
+
+// Logged returns the Records at lvl whose message contains substr.
+func (r *Recorder) Logged(lvl logging.Level, substr string) []Record {
+	var matches []Record
+	for _, record := range r.Records() {
+		if record.Level == lvl && strings.Contains(record.Message, substr) {
+			matches = append(matches, record)
+		}
+	}
+	return matches
+}
+
+// AssertLogged fails the test if nothing was logged at lvl with a message containing substr, and
+// returns the first Record that was.
+func (r *Recorder) AssertLogged(t testing.TB, lvl logging.Level, substr string) Record {
+	t.Helper()
+	matches := r.Logged(lvl, substr)
+	if len(matches) == 0 {
+		t.Errorf("Expected an entry at %s containing %q, got %s instead\n", lvl, substr, r.summary())
+		return Record{}
+	}
+	return matches[0]
+}
+
+// AssertNotLogged fails the test if anything was logged at lvl with a message containing substr.
+func (r *Recorder) AssertNotLogged(t testing.TB, lvl logging.Level, substr string) {
+	t.Helper()
+	if matches := r.Logged(lvl, substr); len(matches) > 0 {
+		t.Errorf("Expected no entry at %s containing %q, got %q\n", lvl, substr, matches[0].Message)
+	}
+}
+
+// summary describes every Record captured, for failure messages.
+func (r *Recorder) summary() string {
+	records := r.Records()
+	if len(records) == 0 {
+		return "no entries"
+	}
+	lines := make([]string, 0, len(records))
+	for _, record := range records {
+		lines = append(lines, "["+record.Level.String()+"] "+record.Message)
+	}
+	return "\n\t" + strings.Join(lines, "\n\t")
+}
+
+// Reporter is a logging.Reporter that keeps the packets reported to it instead of sending them to
+// Sentry. It's safe for concurrent use.
+type Reporter struct {
+	mu      sync.Mutex
+	packets []*raven.Packet
+	tags    []map[string]string
+	closed  bool
+}
+
+// NewReporter returns a Reporter with nothing reported yet. Use it with Logger.WithReporter.
+func NewReporter() *Reporter {
+	return &Reporter{}
+}
+
+// Report implements logging.Reporter. It returns logging.ErrReporterClosed once the Reporter is
+// closed, like the Reporters the logging package provides.
+func (r *Reporter) Report(packet *raven.Packet, tags map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return logging.ErrReporterClosed
+	}
+	copied := make(map[string]string, len(tags))
+	for k, v := range tags {
+		copied[k] = v
+	}
+	r.packets = append(r.packets, packet)
+	r.tags = append(r.tags, copied)
+	return nil
+}
+
+// Close implements logging.Reporter.
+func (r *Reporter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+// Closed returns true if the Reporter has been closed.
+func (r *Reporter) Closed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed
+}
+
+// Packets returns the packets reported so far, oldest first.
+func (r *Reporter) Packets() []*raven.Packet {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*raven.Packet(nil), r.packets...)
+}
+
+// Tags returns the tags each of the packets returned by Packets was reported with.
+func (r *Reporter) Tags() []map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]map[string]string(nil), r.tags...)
+}
+
+// AssertReported fails the test if no packet was reported with a message containing substr, and
+// returns the first packet that was.
+func (r *Reporter) AssertReported(t testing.TB, substr string) *raven.Packet {
+	t.Helper()
+	packets := r.Packets()
+	for _, packet := range packets {
+		if strings.Contains(packet.Message, substr) {
+			return packet
+		}
+	}
+	messages := make([]string, 0, len(packets))
+	for _, packet := range packets {
+		messages = append(messages, packet.Message)
+	}
+	t.Errorf("Expected a packet containing %q to be reported, got %q instead\n", substr, messages)
+	return nil
+}
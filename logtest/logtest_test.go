@@ -0,0 +1,116 @@
+package logtest
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/DramaFever/go-logging"
+)
+
+func TestRecorder(t *testing.T) {
+	log, rec := New(logging.DebugLvl)
+	log = log.WithFlags(logging.CallerShort).AddTags(map[string]string{"region": "us-east-1"})
+
+	_, _, line, _ := runtime.Caller(0)
+	log.AddFields(logging.Int("attempt", 2), logging.Int("attempt", 3)).Debugf("retrying %s", "db")
+	log.Trace("not recorded")
+	log.Infof("connected")
+
+	records := rec.Records()
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d instead\n", len(records))
+	}
+	r := records[0]
+	if r.Level != logging.DebugLvl || r.Message != "retrying db" {
+		t.Errorf("Expected a DEBUG record `retrying db`, got %s `%s` instead\n", r.Level, r.Message)
+	}
+	if r.File != "logtest_test.go" || r.Line != line+1 {
+		t.Errorf("Expected the record to be from logtest_test.go:%d, got %s:%d instead\n", line+1, r.File, r.Line)
+	}
+	if v, ok := r.Field("attempt"); !ok || v != int64(3) {
+		t.Errorf("Expected the last attempt field to win, got %v instead\n", v)
+	}
+	if _, ok := r.Field("missing"); ok {
+		t.Errorf("Expected no field named missing\n")
+	}
+	if r.Tags["region"] != "us-east-1" {
+		t.Errorf("Expected the record to have the Logger's tags, got %v instead\n", r.Tags)
+	}
+	if r.Time.IsZero() {
+		t.Errorf("Expected the record to have a time\n")
+	}
+
+	rec.AssertLogged(t, logging.InfoLvl, "conn")
+	rec.AssertNotLogged(t, logging.TraceLvl, "not recorded")
+	if matches := rec.Logged(logging.InfoLvl, "retrying"); len(matches) != 0 {
+		t.Errorf("Expected records at other Levels not to match, got %d instead\n", len(matches))
+	}
+
+	rec.Reset()
+	if records := rec.Records(); len(records) != 0 {
+		t.Errorf("Expected Reset to discard the records, got %d instead\n", len(records))
+	}
+}
+
+// recordingTB records failures instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, format)
+}
+
+func TestAssertions(t *testing.T) {
+	log, rec := New(logging.InfoLvl)
+	log.Warn("disk almost full")
+
+	tb := &recordingTB{TB: t}
+	rec.AssertLogged(tb, logging.ErrorLvl, "disk")
+	rec.AssertNotLogged(tb, logging.WarnLvl, "disk")
+	rec.AssertLogged(tb, logging.WarnLvl, "disk")
+	if len(tb.errors) != 2 {
+		t.Errorf("Expected 2 failed assertions, got %d instead\n", len(tb.errors))
+	}
+
+	reporter := NewReporter()
+	reporter.AssertReported(tb, "disk")
+	if len(tb.errors) != 3 {
+		t.Errorf("Expected AssertReported to fail with nothing reported\n")
+	}
+}
+
+func TestReporter(t *testing.T) {
+	log, rec := New(logging.InfoLvl)
+	reporter := NewReporter()
+	owner := log.WithReporter(reporter)
+	log = owner.AddTags(map[string]string{"service": "billing"})
+
+	log.Errorf("charging card failed: %v", errors.New("declined"))
+	log.Info("not reported")
+
+	rec.AssertLogged(t, logging.ErrorLvl, "declined")
+	packet := reporter.AssertReported(t, "charging card failed")
+	if packet != nil && !strings.HasSuffix(packet.Message, "declined") {
+		t.Errorf("Expected the packet's message to be interpolated, got `%s` instead\n", packet.Message)
+	}
+	if tags := reporter.Tags(); len(tags) != 1 || tags[0]["service"] != "billing" {
+		t.Errorf("Expected the packet to be reported with the Logger's tags, got %v instead\n", tags)
+	}
+	if packets := reporter.Packets(); len(packets) != 1 {
+		t.Errorf("Expected 1 packet, got %d instead\n", len(packets))
+	}
+
+	owner.Close()
+	if !reporter.Closed() {
+		t.Errorf("Expected the Reporter to be closed with the Logger that introduced it\n")
+	}
+	if err := reporter.Report(packet, nil); err != logging.ErrReporterClosed {
+		t.Errorf("Expected ErrReporterClosed, got %v instead\n", err)
+	}
+}
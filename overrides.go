@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// levelOverrides holds the rules set with WithLevelOverrides, along with the Level each call site
+// that has been checked against them ended up with.
+type levelOverrides struct {
+	rules []overrideRule
+	sites sync.Map // program counter of the call site -> siteLevel
+}
+
+type overrideRule struct {
+	pattern  string
+	segments int
+	file     bool
+	level    Level
+}
+
+type siteLevel struct {
+	level    Level
+	override bool
+}
+
+// WithLevelOverrides returns a copy of l that uses a different Level for statements logged from
+// certain packages or files, so one noisy subsystem can be turned up to DebugLvl without turning
+// everything else up with it. The spec is a comma-separated list of pattern=LEVEL rules, like
+//
+//	myapp/db=DEBUG,myapp/cache/*=WARN,handlers_test.go=TRACE
+//
+// Patterns ending in ".go" are matched against the file the statement was logged from, and all other
+// patterns against its package's import path. Either way, a pattern matches the end of the path, one
+// path element at a time, so "myapp/db" matches the package github.com/acme/myapp/db but not
+// github.com/acme/myapp/db/migrations. Patterns can contain the wildcards path.Match understands. The
+// first rule that matches a statement decides its Level; statements no rule matches use the Level of
+// the Logger, whether it's static or a LevelVar.
+//
+// The rules are checked once per call site, and the result is remembered, so overrides cost a stack
+// lookup and a map lookup per statement. An empty spec removes all overrides.
+func (l Logger) WithLevelOverrides(spec string) (Logger, error) {
+	l.owns = nil
+	overrides, err := parseLevelOverrides(spec)
+	if err != nil {
+		return l, err
+	}
+	l.overrides = overrides
+	return l, nil
+}
+
+func parseLevelOverrides(spec string) (*levelOverrides, error) {
+	var rules []overrideRule
+	for _, rule := range strings.Split(spec, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		pos := strings.LastIndex(rule, "=")
+		if pos < 1 {
+			return nil, fmt.Errorf("logging: level override %q must be in the form pattern=LEVEL", rule)
+		}
+		pattern := strings.Trim(rule[:pos], "/")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("logging: level override %q has an invalid pattern: %s", rule, err)
+		}
+		lvl, err := ParseLevel(rule[pos+1:])
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, overrideRule{
+			pattern:  pattern,
+			segments: strings.Count(pattern, "/") + 1,
+			file:     strings.HasSuffix(pattern, ".go"),
+			level:    lvl,
+		})
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &levelOverrides{rules: rules}, nil
+}
+
+// forCaller returns the Level that applies to statements logged from the call site pc, and whether
+// any rule matched it at all.
+func (o *levelOverrides) forCaller(pc uintptr) (Level, bool) {
+	if site, ok := o.sites.Load(pc); ok {
+		return site.(siteLevel).level, site.(siteLevel).override
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkg := packagePath(frame.Function)
+	var site siteLevel
+	for _, rule := range o.rules {
+		if rule.matches(pkg, frame.File) {
+			site = siteLevel{level: rule.level, override: true}
+			break
+		}
+	}
+	o.sites.Store(pc, site)
+	return site.level, site.override
+}
+
+func (r overrideRule) matches(pkg, file string) bool {
+	target := pkg
+	if r.file {
+		target = file
+	}
+	pos := len(target)
+	for i := 0; i < r.segments; i++ {
+		pos = strings.LastIndex(target[:pos], "/")
+		if pos < 0 {
+			if i < r.segments-1 {
+				return false
+			}
+			break
+		}
+	}
+	matched, _ := path.Match(r.pattern, target[pos+1:])
+	return matched
+}
+
+// packagePath returns the import path of the package a function belongs to, given the function's
+// fully-qualified name as reported by the runtime, like "github.com/acme/myapp/db.(*Store).Get".
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return function
+	}
+	return function[:slash+1+dot]
+}
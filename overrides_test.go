@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOverrideRuleMatches(t *testing.T) {
+	type matchTest struct {
+		pattern, pkg, file string
+		matches            bool
+	}
+	const file = "/home/builder/go/src/github.com/acme/myapp/db/store.go"
+	matchTests := []matchTest{
+		{pattern: "myapp/db", pkg: "github.com/acme/myapp/db", file: file, matches: true},
+		{pattern: "db", pkg: "github.com/acme/myapp/db", file: file, matches: true},
+		{pattern: "github.com/acme/myapp/db", pkg: "github.com/acme/myapp/db", file: file, matches: true},
+		{pattern: "myapp/db", pkg: "github.com/acme/myapp/db/migrations", file: file, matches: false},
+		{pattern: "yapp/db", pkg: "github.com/acme/myapp/db", file: file, matches: false},
+		{pattern: "myapp/*", pkg: "github.com/acme/myapp/db", file: file, matches: true},
+		{pattern: "myapp/cache/*", pkg: "github.com/acme/myapp/db", file: file, matches: false},
+		{pattern: "acme/myapp/db/extra", pkg: "myapp/db", file: file, matches: false},
+		{pattern: "main", pkg: "main", file: "/src/main.go", matches: true},
+		{pattern: "store.go", pkg: "github.com/acme/myapp/db", file: file, matches: true},
+		{pattern: "db/*.go", pkg: "github.com/acme/myapp/db", file: file, matches: true},
+		{pattern: "*_test.go", pkg: "github.com/acme/myapp/db", file: file, matches: false},
+	}
+	for _, test := range matchTests {
+		overrides, err := parseLevelOverrides(test.pattern + "=DEBUG")
+		if err != nil {
+			t.Errorf("Unexpected error: %+v\n", err)
+			continue
+		}
+		if matches := overrides.rules[0].matches(test.pkg, test.file); matches != test.matches {
+			t.Errorf("Expected match to be %t, got %t from %+v\n", test.matches, matches, test)
+		}
+	}
+}
+
+func TestParseLevelOverridesErrors(t *testing.T) {
+	for _, spec := range []string{"myapp/db", "=DEBUG", "myapp/db=LOUD", "myapp/[=DEBUG"} {
+		if _, err := parseLevelOverrides(spec); err == nil {
+			t.Errorf("Expected an error parsing %q\n", spec)
+		}
+	}
+	overrides, err := parseLevelOverrides(" , ")
+	if err != nil || overrides != nil {
+		t.Errorf("Expected an empty spec to remove overrides, got %+v, %+v\n", overrides, err)
+	}
+}
+
+func TestLevelOverrides(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(WarnLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log, err = log.WithLevelOverrides("go-logging=DEBUG")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for i := 0; i < 2; i++ {
+		log.Debugf("Test number %d", i)
+		log.Trace("Not written")
+	}
+	if strings.Count(buf.String(), "[DEBUG]") != 2 || strings.Contains(buf.String(), "Not written") {
+		t.Errorf("Expected overridden DebugLvl lines only, got `%s`\n", buf.String())
+	}
+
+	buf.Reset()
+	log, err = log.WithLevelOverrides("nothing/matches=TRACE,overrides_test.go=ERROR")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Warn("Not written")
+	log.Errorf("Written")
+	if buf.String() == "" || strings.Contains(buf.String(), "Not written") {
+		t.Errorf("Expected file override to exclude WarnLvl, got `%s`\n", buf.String())
+	}
+
+	buf.Reset()
+	log, err = log.WithLevelOverrides("")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Debug("Not written")
+	if buf.Len() != 0 {
+		t.Errorf("Expected overrides to be removed, got `%s`\n", buf.String())
+	}
+}
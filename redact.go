@@ -0,0 +1,277 @@
+package logging
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/getsentry/raven-go"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret is a string that is never written to logs or sent to Sentry. However it's formatted, it
+// renders as "[REDACTED]", so wrapping a value in Secret is enough to keep it out of log messages:
+//
+//	l.Debugf("authenticating with key %s", logging.Secret(apiKey))
+type Secret string
+
+// String implements fmt.Stringer, always returning "[REDACTED]".
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer, always returning "[REDACTED]".
+func (s Secret) GoString() string {
+	return redacted
+}
+
+// Format implements fmt.Formatter, so even verbs like %q and %x render "[REDACTED]".
+func (s Secret) Format(f fmt.State, verb rune) {
+	f.Write([]byte(redacted))
+}
+
+// MarshalText implements encoding.TextMarshaler, always returning "[REDACTED]".
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// MarshalJSON implements json.Marshaler, always returning "[REDACTED]" as a JSON string.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// DefaultRedactedFields are the names of the fields DefaultRedactor redacts.
+var DefaultRedactedFields = []string{"password", "passwd", "passphrase", "secret", "token", "authorization", "cookie", "api_key", "apikey", "api-key"}
+
+var (
+	// EmailPattern matches email addresses.
+	EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// BearerTokenPattern matches bearer tokens, like those in an Authorization header.
+	BearerTokenPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]+=*`)
+	// CreditCardPattern matches 13 to 19 digits, optionally separated by spaces or dashes, like credit
+	// card numbers are. DefaultRedactor only redacts matches that pass the Luhn check, so other long
+	// numbers, like timestamps, are left alone.
+	CreditCardPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+)
+
+// Redactor scrubs secrets and personal information out of log lines and Sentry events before they
+// leave the process. It applies two kinds of rules:
+//
+// Field rules redact the value of anything whose name contains one of the Redactor's field names,
+// ignoring case. They apply to fields, Sentry tags, the headers, cookies, query string, and environment of
+// HTTP requests sent to Sentry (including those passed as arguments, which raven.NewHttp copies
+// wholesale), and key=value or key: value pairs inside messages.
+//
+// Pattern rules redact every match of a regular expression, wherever it appears: in messages, in the
+// parameters and exceptions sent to Sentry, and in the values of tags, headers, and metadata.
+//
+// Values wrapped in Secret are always redacted, whether a Logger has a Redactor or not.
+type Redactor struct {
+	names   []string
+	kv      *regexp.Regexp
+	pattern []redactRule
+}
+
+type redactRule struct {
+	re    *regexp.Regexp
+	valid func(string) bool
+}
+
+// NewRedactor returns a Redactor that redacts the values of fields whose names contain any of
+// fields, and anything matching any of patterns.
+func NewRedactor(fields []string, patterns ...*regexp.Regexp) *Redactor {
+	r := &Redactor{}
+	var quoted []string
+	for _, field := range fields {
+		if field == "" {
+			continue
+		}
+		r.names = append(r.names, strings.ToLower(field))
+		quoted = append(quoted, regexp.QuoteMeta(field))
+	}
+	if len(quoted) > 0 {
+		r.kv = regexp.MustCompile(`(?i)([\w.-]*(?:` + strings.Join(quoted, "|") + `)[\w.-]*"?\s*[=:]\s*)((?:(?:basic|bearer|digest)\s+)?(?:"[^"]*"|[^\s,&;]+))`)
+	}
+	for _, re := range patterns {
+		rule := redactRule{re: re}
+		if re == CreditCardPattern {
+			rule.valid = luhn
+		}
+		r.pattern = append(r.pattern, rule)
+	}
+	return r
+}
+
+// DefaultRedactor returns a Redactor for DefaultRedactedFields, email addresses, bearer tokens, and
+// credit card numbers.
+func DefaultRedactor() *Redactor {
+	return NewRedactor(DefaultRedactedFields, EmailPattern, BearerTokenPattern, CreditCardPattern)
+}
+
+// WithRedactor returns a copy of l that passes everything it writes or sends to Sentry through r
+// first. A nil Redactor turns redaction off.
+func (l Logger) WithRedactor(r *Redactor) Logger {
+	l.owns = nil
+	l.redactor = r
+	return l
+}
+
+// String returns s with every key=value pair named by a field rule and every match of a pattern rule
+// redacted.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, rule := range r.pattern {
+		if rule.valid == nil {
+			s = rule.re.ReplaceAllString(s, redacted)
+			continue
+		}
+		s = rule.re.ReplaceAllStringFunc(s, func(match string) string {
+			if !rule.valid(match) {
+				return match
+			}
+			return redacted
+		})
+	}
+	if r.kv != nil {
+		s = r.kv.ReplaceAllString(s, "${1}"+redacted)
+	}
+	return s
+}
+
+// sensitive returns true if a field rule applies to fields called name.
+func (r *Redactor) sensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, field := range r.names {
+		if strings.Contains(name, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// field returns value redacted according to the rules for a field called name.
+func (r *Redactor) field(name, value string) string {
+	if r.sensitive(name) {
+		return redacted
+	}
+	return r.String(value)
+}
+
+func (r *Redactor) fieldMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = r.field(k, v)
+	}
+	return out
+}
+
+func (r *Redactor) query(query string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return r.String(query)
+	}
+	for k, vals := range values {
+		for i, v := range vals {
+			vals[i] = r.field(k, v)
+		}
+		values[k] = vals
+	}
+	return values.Encode()
+}
+
+// fields returns a redacted copy of fields. Values that could hold anything are redacted as text;
+// numbers, bools, and durations are left alone unless their name calls for redaction.
+func (r *Redactor) fields(fields []Field) []Field {
+	out := make([]Field, len(fields))
+	for i, f := range fields {
+		switch {
+		case r.sensitive(f.Key):
+			out[i] = String(f.Key, redacted)
+		case f.kind == stringKind:
+			out[i] = String(f.Key, r.String(f.str))
+		case f.kind == errorKind && f.val != nil:
+			out[i] = String(f.Key, r.String(f.val.(error).Error()))
+		case f.kind == anyKind:
+			out[i] = String(f.Key, r.String(fmt.Sprint(f.val)))
+		default:
+			out[i] = f
+		}
+	}
+	return out
+}
+
+// params returns a redacted copy of the parameters of a message, rendered as strings.
+func (r *Redactor) params(args []interface{}) []interface{} {
+	out := make([]interface{}, len(args))
+	for i, arg := range args {
+		out[i] = r.String(fmt.Sprint(arg))
+	}
+	return out
+}
+
+// sentryInterface returns a redacted copy of i. Interfaces the Redactor doesn't know how to inspect
+// are returned as-is.
+func (r *Redactor) sentryInterface(i raven.Interface) raven.Interface {
+	switch v := i.(type) {
+	case *raven.Message:
+		return &raven.Message{Message: r.String(v.Message), Params: r.params(v.Params)}
+	case *raven.Exception:
+		e := *v
+		e.Value = r.String(e.Value)
+		return &e
+	case *raven.Http:
+		h := *v
+		h.URL = r.String(h.URL)
+		h.Query = r.query(h.Query)
+		h.Cookies = r.field("cookie", h.Cookies)
+		h.Headers = r.fieldMap(h.Headers)
+		h.Env = r.fieldMap(h.Env)
+		switch data := h.Data.(type) {
+		case string:
+			h.Data = r.String(data)
+		case map[string]string:
+			h.Data = r.fieldMap(data)
+		}
+		return &h
+	case *raven.User:
+		u := *v
+		u.Username = r.String(u.Username)
+		u.Email = r.String(u.Email)
+		return &u
+	case *raven.Query:
+		q := *v
+		q.Query = r.String(q.Query)
+		return &q
+	}
+	return i
+}
+
+// luhn returns true if the digits in s pass the Luhn checksum used by credit card numbers.
+func luhn(s string) bool {
+	var sum, digits int
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && sum%10 == 0
+}
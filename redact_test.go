@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/getsentry/raven-go"
+)
+
+func TestSecret(t *testing.T) {
+	s := Secret("hunter2")
+	for _, verb := range []string{"%s", "%v", "%+v", "%#v", "%q", "%x", "%d", "%10s"} {
+		if out := fmt.Sprintf(verb, s); out != "[REDACTED]" {
+			t.Errorf("Expected %s to render [REDACTED], got `%s` instead\n", verb, out)
+		}
+	}
+	out, err := json.Marshal(map[string]interface{}{"key": s})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if string(out) != `{"key":"[REDACTED]"}` {
+		t.Errorf("Expected JSON to be redacted, got `%s` instead\n", out)
+	}
+}
+
+func TestRedactorString(t *testing.T) {
+	r := DefaultRedactor()
+	redactTests := map[string]string{
+		"login failed for bob@example.com":          "login failed for [REDACTED]",
+		"sent Authorization: Bearer abc.def-ghi==":  "sent Authorization: [REDACTED]",
+		"header authorization=Basic Zm9vOmJhcg==":   "header authorization=[REDACTED]",
+		"charged 4111 1111 1111 1111 today":         "charged [REDACTED] today",
+		"charged 4111-1111-1111-1112 today":         "charged 4111-1111-1111-1112 today",
+		"took 1500000000123456789ns":                "took 1500000000123456789ns",
+		"user=bob password=hunter2&token=abc":       "user=bob password=[REDACTED]&token=[REDACTED]",
+		`{"db_password": "hunter2", "user": "bob"}`: `{"db_password": [REDACTED], "user": "bob"}`,
+		"nothing to see here":                       "nothing to see here",
+	}
+	for in, expected := range redactTests {
+		if out := r.String(in); out != expected {
+			t.Errorf("Expected `%s` to be redacted to `%s`, got `%s` instead\n", in, expected, out)
+		}
+	}
+	custom := NewRedactor(nil, regexp.MustCompile(`sk_live_\w+`))
+	if out := custom.String("key sk_live_abc123 password=x"); out != "key [REDACTED] password=x" {
+		t.Errorf("Expected only the custom pattern to be redacted, got `%s` instead\n", out)
+	}
+}
+
+func TestRedactorSentryInterfaces(t *testing.T) {
+	r := DefaultRedactor()
+	req := httptest.NewRequest("GET", "https://example.com/users?token=abc&page=2", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	req.Header.Set("X-Api-Key", "12345")
+	req.Header.Set("Cookie", "session=abc")
+	req.Header.Set("Referer", "mailto:bob@example.com")
+	req.Header.Set("Accept", "text/plain")
+	original := raven.NewHttp(req)
+	h := r.sentryInterface(original).(*raven.Http)
+	for _, header := range []string{"Authorization", "X-Api-Key", "Cookie"} {
+		if h.Headers[header] != "[REDACTED]" {
+			t.Errorf("Expected header %s to be redacted, got `%s` instead\n", header, h.Headers[header])
+		}
+	}
+	if h.Headers["Referer"] != "mailto:[REDACTED]" || h.Headers["Accept"] != "text/plain" {
+		t.Errorf("Expected other headers to be matched against patterns only, got %+v\n", h.Headers)
+	}
+	if h.Cookies != "[REDACTED]" || h.Query != "page=2&token=%5BREDACTED%5D" {
+		t.Errorf("Expected cookies and query string to be redacted, got `%s` and `%s`\n", h.Cookies, h.Query)
+	}
+	if original.Headers["Authorization"] != "Bearer abc" {
+		t.Errorf("Expected the original interface to be left alone, got %+v\n", original.Headers)
+	}
+
+	msg := r.sentryInterface(&raven.Message{Message: "hi %s %s", Params: []interface{}{"bob@example.com", Secret("x")}}).(*raven.Message)
+	if msg.Params[0] != "[REDACTED]" || msg.Params[1] != "[REDACTED]" {
+		t.Errorf("Expected message params to be redacted, got %+v\n", msg.Params)
+	}
+	exc := r.sentryInterface(raven.NewException(fmt.Errorf("no user bob@example.com"), nil)).(*raven.Exception)
+	if exc.Value != "no user [REDACTED]" {
+		t.Errorf("Expected exception value to be redacted, got `%s` instead\n", exc.Value)
+	}
+	tags := r.fieldMap(map[string]string{"session_token": "abc", "contact": "bob@example.com", "region": "us"})
+	if tags["session_token"] != "[REDACTED]" || tags["contact"] != "[REDACTED]" || tags["region"] != "us" {
+		t.Errorf("Expected tags to be redacted, got %+v\n", tags)
+	}
+}
+
+func TestLoggerRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Infof("key %s", Secret("hunter2"))
+	log = log.WithRedactor(DefaultRedactor())
+	log.Infof("password=%s for %s", "hunter2", "bob@example.com")
+	if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "bob@example.com") {
+		t.Errorf("Expected output to be redacted, got `%s`\n", buf.String())
+	}
+	if !strings.HasSuffix(buf.String(), "password=[REDACTED] for [REDACTED]\n") {
+		t.Errorf("Expected redacted message, got `%s`\n", buf.String())
+	}
+}
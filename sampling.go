@@ -0,0 +1,166 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sampler decides which statements make it past WithSampling. Each distinct combination of Level and
+// message template gets its own window, which starts with the first statement that uses it. Windows
+// that have ended are swept away once per interval, so statements that are never repeated don't pile
+// up.
+type sampler struct {
+	interval          time.Duration
+	first, thereafter uint64
+
+	mu        sync.Mutex
+	windows   map[sampleKey]*sampleWindow
+	pending   map[*sampleWindow]*time.Timer
+	lastSweep time.Time
+}
+
+// sampleKey identifies a group of statements. Statements logged with a format string are grouped by
+// it; the others are grouped by their call site, since their messages are often different every time.
+type sampleKey struct {
+	lvl      Level
+	template string
+	pc       uintptr
+}
+
+type sampleWindow struct {
+	key           sampleKey
+	pc            uintptr // call site of the window's first statement, which its summary is attributed to
+	example       string
+	start         time.Time
+	seen, dropped uint64
+}
+
+// WithSampling returns a copy of l that throttles repetitive statements, which otherwise can swamp
+// the disk and the log indexer during an incident. Statements are grouped by Level and message
+// template (the format string for the formatting methods, the line of code for the others), and for
+// each group the first `first` statements in every interval are written, then every `thereafter`th
+// statement after that. If thereafter is 0, nothing more is written until the interval ends.
+//
+// Dropped statements aren't sent to Sentry either. When an interval in which statements were dropped
+// ends, a line saying how many were dropped is written in their place. Statements logged with the
+// Panic and Fatal families of methods are never dropped.
+//
+// All Loggers derived from the returned Logger share its sampling, and their summaries are written
+// when the Logger is closed, if their intervals haven't ended by then. An interval of 0 or less turns
+// sampling off.
+func (l Logger) WithSampling(interval time.Duration, first, thereafter int) Logger {
+	l.owns = nil
+	if interval <= 0 {
+		l.sampler = nil
+		return l
+	}
+	if first < 0 {
+		first = 0
+	}
+	if thereafter < 0 {
+		thereafter = 0
+	}
+	l.sampler = &sampler{
+		interval:   interval,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+		windows:    map[sampleKey]*sampleWindow{},
+		pending:    map[*sampleWindow]*time.Timer{},
+	}
+	return l
+}
+
+// sampled returns true if a statement logged at lvl should be written, according to l's sampling.
+// The statement is identified by its format string, or, if it has none, by its call site. It must be
+// called directly from the method the statement was logged with, so it can find the call site.
+func (l Logger) sampled(lvl Level, format string, msg []interface{}) bool {
+	if l.sampler == nil {
+		return true
+	}
+	return l.sampledAt(lvl, format, msg, callSite(l.calldepth+2))
+}
+
+// sampledAt is sampled, for statements whose call site is already known. A pc of 0 means the call
+// site is unknown.
+func (l Logger) sampledAt(lvl Level, format string, msg []interface{}, pc uintptr) bool {
+	if l.sampler == nil {
+		return true
+	}
+	key := sampleKey{lvl: lvl, template: format}
+	if format == "" {
+		key.pc = pc
+	}
+	return l.sampler.allow(l, key, msg, pc)
+}
+
+func (s *sampler) allow(l Logger, key sampleKey, msg []interface{}, pc uintptr) bool {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= s.interval {
+		s.sweep(now)
+	}
+	w := s.windows[key]
+	if w == nil || now.Sub(w.start) >= s.interval {
+		w = &sampleWindow{key: key, pc: pc, example: key.template, start: now}
+		if key.pc != 0 {
+			w.example = strings.TrimSuffix(sprintln(msg), "\n")
+		}
+		s.windows[key] = w
+	}
+	w.seen++
+	if w.seen <= s.first || (s.thereafter > 0 && (w.seen-s.first)%s.thereafter == 0) {
+		return true
+	}
+	w.dropped++
+	if w.dropped == 1 {
+		s.pending[w] = time.AfterFunc(w.start.Add(s.interval).Sub(now), func() {
+			s.mu.Lock()
+			delete(s.pending, w)
+			s.mu.Unlock()
+			s.report(l, w)
+		})
+	}
+	return false
+}
+
+// sweep forgets the windows that ended without dropping anything. Windows that dropped statements are
+// forgotten when their summaries are written. It must be called with s.mu held.
+func (s *sampler) sweep(now time.Time) {
+	s.lastSweep = now
+	for key, w := range s.windows {
+		if w.dropped == 0 && now.Sub(w.start) >= s.interval {
+			delete(s.windows, key)
+		}
+	}
+}
+
+// report writes the summary of the statements dropped in w, attributed to the first of them. Once
+// it's called, w is no longer the current window for its key, so w.dropped can't change any more.
+func (s *sampler) report(l Logger, w *sampleWindow) {
+	s.mu.Lock()
+	if s.windows[w.key] == w {
+		delete(s.windows, w.key)
+	}
+	s.mu.Unlock()
+	msg := fmt.Sprintf("logging: dropped %d of %d %s lines like %q in %s", w.dropped, w.seen, w.key.lvl, w.example, s.interval)
+	l.logged(l.write(l.now(), w.pc, msg, w.key.lvl))
+}
+
+// flush ends every window that has dropped statements, writing their summaries immediately.
+func (s *sampler) flush(l Logger) {
+	s.mu.Lock()
+	var windows []*sampleWindow
+	for w, timer := range s.pending {
+		if timer.Stop() {
+			windows = append(windows, w)
+		}
+		delete(s.pending, w)
+	}
+	s.mu.Unlock()
+	for _, w := range windows {
+		s.report(l, w)
+	}
+}
@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer that can be read while a Logger writes to it from another goroutine.
+type lockedBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.String()
+}
+
+func TestSampling(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithSampling(time.Hour, 2, 3)
+	_, _, line, _ := runtime.Caller(0)
+	for i := 0; i < 10; i++ {
+		log.Warnf("upstream %s unavailable", "db")
+		log.Info("different template")
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("Expected 4 lines per template, got `%s`\n", buf.String())
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "dropped") {
+			t.Errorf("Expected no summary before the interval ends, got `%s`\n", line)
+		}
+	}
+
+	buf.Reset()
+	log.Close()
+	expected := []string{
+		`[WARN]`, fmt.Sprintf(`sampling_test.go:%d: logging: dropped 6 of 10 WARN lines like "upstream %%s unavailable" in 1h0m0s`, line+2),
+		`[INFO]`, fmt.Sprintf(`sampling_test.go:%d: logging: dropped 6 of 10 INFO lines like "different template" in 1h0m0s`, line+3),
+	}
+	for _, e := range expected {
+		if !strings.Contains(buf.String(), e) {
+			t.Errorf("Expected summary containing `%s` on Close, got `%s`\n", e, buf.String())
+		}
+	}
+}
+
+func TestSamplingInterval(t *testing.T) {
+	var buf lockedBuffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithSampling(20*time.Millisecond, 1, 0)
+	for i := 0; i < 5; i++ {
+		log.Errorf("Test number %d", i)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "dropped") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(buf.String(), `logging: dropped 4 of 5 ERROR lines like "Test number %d" in 20ms`) {
+		t.Fatalf("Expected a summary once the interval ended, got `%s`\n", buf.String())
+	}
+	log.Errorf("Test number %d", 5)
+	if strings.Count(buf.String(), "Test number 5") != 1 {
+		t.Errorf("Expected a new interval to let statements through again, got `%s`\n", buf.String())
+	}
+}
+
+func TestSamplingCallSite(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithSampling(time.Hour, 1, 0)
+	for i := 0; i < 5; i++ {
+		log.Info("request", i, "done")
+	}
+	log.Info("request", 5, "done")
+	if strings.Count(buf.String(), "\n") != 2 {
+		t.Errorf("Expected dynamic messages to be sampled by call site, got `%s`\n", buf.String())
+	}
+
+	buf.Reset()
+	log.Close()
+	if !strings.Contains(buf.String(), `logging: dropped 4 of 5 INFO lines like "request 0 done"`) {
+		t.Errorf("Expected the summary to quote the first message, got `%s`\n", buf.String())
+	}
+}
+
+func TestSamplingSweep(t *testing.T) {
+	log, err := New(InfoLvl, &bytes.Buffer{}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithSampling(time.Millisecond, 1, 0)
+	for i := 0; i < 100; i++ {
+		log.Infof("unique template %d"+strings.Repeat(" ", i), i)
+	}
+	time.Sleep(2 * time.Millisecond)
+	log.Infof("after the interval")
+	log.sampler.mu.Lock()
+	windows := len(log.sampler.windows)
+	log.sampler.mu.Unlock()
+	if windows != 1 {
+		t.Errorf("Expected windows that ended to be swept, got %d windows instead\n", windows)
+	}
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/getsentry/raven-go"
+
+	"github.com/DramaFever/go-logging/sentrytest"
+)
+
+func TestSentryEvents(t *testing.T) {
+	srv := sentrytest.NewServer()
+	defer srv.Close()
+	log, err := New(InfoLvl, &bytes.Buffer{}, srv.DSN(), map[string]string{"service": "billing"})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defer log.Close()
+
+	req, _ := http.NewRequest("POST", "http://example.com/charge?card=visa", nil)
+	l := log.WithRelease("1.2.3").
+		AddTags(map[string]string{"region": "us-east-1"}).
+		AddMeta(raven.NewHttp(req)).
+		AddFields(Int("attempt", 2))
+
+	_, _, line, _ := runtime.Caller(0)
+	l.Warnf("charge for %s retried", "order-1")
+	l.Info("not reported")
+
+	events := srv.Events()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d instead: %v\n", len(events), srv.Errors())
+	}
+	e := events[0]
+	if e.Message != "charge for order-1 retried" || e.Level != "warning" || e.Release != "1.2.3" {
+		t.Errorf("Expected a warning `charge for order-1 retried` from release 1.2.3, got %s `%s` from %q instead\n", e.Level, e.Message, e.Release)
+	}
+	if e.LogEntry == nil || e.LogEntry.Message != "charge for %s retried" || len(e.LogEntry.Params) != 1 {
+		t.Errorf("Expected the format string and its arguments to be reported, got %+v instead\n", e.LogEntry)
+	}
+	if e.Tags["service"] != "billing" || e.Tags["region"] != "us-east-1" {
+		t.Errorf("Expected the client's tags and the Logger's tags, got %v instead\n", e.Tags)
+	}
+	if e.Extra["attempt"] != float64(2) {
+		t.Errorf("Expected the fields as extra data, got %v instead\n", e.Extra)
+	}
+	if e.Request == nil || e.Request.Method != "POST" || e.Request.QueryString != "card=visa" {
+		t.Errorf("Expected the request added with AddMeta, got %+v instead\n", e.Request)
+	}
+	if e.Stacktrace == nil || len(e.Stacktrace.Frames) == 0 {
+		t.Fatal("Expected the event to have a stacktrace")
+	}
+	if frame := e.Stacktrace.Frames[len(e.Stacktrace.Frames)-1]; frame.Function != "TestSentryEvents" || frame.Lineno != line+1 {
+		t.Errorf("Expected the stacktrace to start at TestSentryEvents line %d, got %s line %d instead\n", line+1, frame.Function, frame.Lineno)
+	}
+}
+
+func TestSentryExceptions(t *testing.T) {
+	srv := sentrytest.NewServer()
+	defer srv.Close()
+	log, err := New(InfoLvl, &bytes.Buffer{}, srv.DSN(), nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defer log.Close()
+
+	log.Errorf("charge failed: %v", errors.New("card declined"))
+	log.Error("refund failed")
+
+	events := srv.Events()
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d instead: %v\n", len(events), srv.Errors())
+	}
+	if e := events[0]; e.Level != "error" || len(e.Exceptions) != 1 || e.Exceptions[0].Value != "card declined" || e.Exceptions[0].Stacktrace == nil {
+		t.Errorf("Expected the error argument to be reported as an exception, got %+v instead\n", e.Exceptions)
+	}
+	if e := events[1]; strings.TrimSpace(e.Message) != "refund failed" || len(e.Exceptions) != 0 {
+		t.Errorf("Expected `refund failed` without an exception, got `%s` with %+v instead\n", e.Message, e.Exceptions)
+	}
+}
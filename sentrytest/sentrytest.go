@@ -0,0 +1,400 @@
+// Package sentrytest provides a fake Sentry server for tests. It accepts events on the store and
+// envelope endpoints, the way Sentry itself does, decodes them, and keeps them for the test to
+// inspect, so code that reports to Sentry can be tested without a real DSN or network access:
+//
+//	func TestReport(t *testing.T) {
+//		srv := sentrytest.NewServer()
+//		defer srv.Close()
+//		log, err := logging.New(logging.InfoLvl, os.Stdout, srv.DSN(), nil)
+//		...
+//		log.Errorf("charge failed: %v", err)
+//		events := srv.Events()
+//		...
+//	}
+package sentrytest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Event is an event received by a Server, decoded from the JSON the client sent. Events sent by older
+// clients, which name interfaces like "sentry.interfaces.Http", are decoded the same as those sent by
+// newer ones, which name them like "request".
+type Event struct {
+	EventID     string
+	Message     string
+	Level       string
+	Logger      string
+	Culprit     string
+	Release     string
+	Environment string
+	ServerName  string
+	Platform    string
+	Tags        map[string]string
+	Extra       map[string]interface{}
+	Fingerprint []string
+
+	// LogEntry is the message interface, with the format string and the parameters it was
+	// interpolated with.
+	LogEntry *LogEntry
+	// Exceptions are the exceptions in the event, innermost first, as Sentry orders them.
+	Exceptions []Exception
+	// Stacktrace is the stacktrace of the event itself, as opposed to one of its exceptions.
+	Stacktrace *Stacktrace
+	// Request is the HTTP interface, describing the request the event happened during.
+	Request *Request
+
+	// Raw is the whole event, for assertions about anything the other fields don't cover.
+	Raw map[string]json.RawMessage
+}
+
+// LogEntry is the message interface of an Event.
+type LogEntry struct {
+	Message   string        `json:"message"`
+	Params    []interface{} `json:"params"`
+	Formatted string        `json:"formatted"`
+}
+
+// Exception is an exception in an Event.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Module     string      `json:"module"`
+	Stacktrace *Stacktrace `json:"stacktrace"`
+}
+
+// Stacktrace is a stacktrace in an Event. The frames are ordered oldest call first, so the frame
+// that reported the event, or raised the exception, is last.
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is a frame of a Stacktrace.
+type Frame struct {
+	Filename     string `json:"filename"`
+	AbsolutePath string `json:"abs_path"`
+	Function     string `json:"function"`
+	Module       string `json:"module"`
+	Lineno       int    `json:"lineno"`
+	InApp        bool   `json:"in_app"`
+}
+
+// Request is the HTTP interface of an Event.
+type Request struct {
+	URL         string            `json:"url"`
+	Method      string            `json:"method"`
+	QueryString string            `json:"query_string"`
+	Cookies     string            `json:"cookies"`
+	Headers     map[string]string `json:"headers"`
+	Env         map[string]string `json:"env"`
+	Data        interface{}       `json:"data"`
+}
+
+// Server is a fake Sentry server, listening on a port on the loopback interface. It's safe for
+// concurrent use.
+type Server struct {
+	srv *httptest.Server
+
+	mu     sync.Mutex
+	events []Event
+	errs   []error
+}
+
+// NewServer starts and returns a new Server. Close it when the test is done with it.
+func NewServer() *Server {
+	s := &Server{}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// DSN returns a DSN that sends events to s, for project 1.
+func (s *Server) DSN() string {
+	return strings.Replace(s.srv.URL, "://", "://public:secret@", 1) + "/1"
+}
+
+// Close shuts s down, waiting for any requests in flight.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Events returns the events s has received, oldest first.
+func (s *Server) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.events...)
+}
+
+// Errors returns the reasons s rejected any requests it couldn't make sense of. Rejected requests
+// get a 400 response, with the reason in the X-Sentry-Error header, like Sentry sends.
+func (s *Server) Errors() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]error(nil), s.errs...)
+}
+
+// Reset discards the events and errors s has kept so far.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = nil
+	s.errs = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	events, err := s.decodeRequest(r)
+	s.mu.Lock()
+	if err != nil {
+		s.errs = append(s.errs, err)
+	} else {
+		s.events = append(s.events, events...)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		w.Header().Set("X-Sentry-Error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	id := ""
+	if len(events) > 0 {
+		id = events[0].EventID
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id": id})
+}
+
+func (s *Server) decodeRequest(r *http.Request) ([]Event, error) {
+	if r.Method != "POST" {
+		return nil, fmt.Errorf("unexpected method %s", r.Method)
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/store/"):
+		event, err := decodeEvent(body)
+		if err != nil {
+			return nil, err
+		}
+		return []Event{event}, nil
+	case strings.HasSuffix(r.URL.Path, "/envelope/"):
+		return decodeEnvelope(body)
+	}
+	return nil, fmt.Errorf("unexpected path %s", r.URL.Path)
+}
+
+// readBody returns the body of r, undoing any compression. Older clients send large events deflated
+// and base64-encoded, with a Content-Type of application/octet-stream; newer ones use
+// Content-Encoding.
+func readBody(r *http.Request) ([]byte, error) {
+	var body io.Reader = r.Body
+	switch r.Header.Get("Content-Encoding") {
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing body: %v", err)
+		}
+		body = gz
+	case "deflate":
+		z, err := zlib.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing body: %v", err)
+		}
+		body = z
+	}
+	if r.Header.Get("Content-Type") == "application/octet-stream" {
+		z, err := zlib.NewReader(base64.NewDecoder(base64.StdEncoding, body))
+		if err != nil {
+			return nil, fmt.Errorf("decompressing body: %v", err)
+		}
+		body = z
+	}
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("reading body: %v", err)
+	}
+	return b, nil
+}
+
+// decodeEnvelope returns the events in an envelope: a header, followed by items that each have a
+// header of their own and a payload. Items other than events are skipped.
+func decodeEnvelope(body []byte) ([]Event, error) {
+	reader := bufio.NewReader(bytes.NewReader(body))
+	if _, err := reader.ReadBytes('\n'); err != nil {
+		return nil, errors.New("envelope has no items")
+	}
+	var events []Event
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err != nil {
+				return events, nil
+			}
+			continue
+		}
+		var header struct {
+			Type   string `json:"type"`
+			Length *int   `json:"length"`
+		}
+		if err := json.Unmarshal(line, &header); err != nil {
+			return nil, fmt.Errorf("decoding envelope item header: %v", err)
+		}
+		var payload []byte
+		if header.Length != nil {
+			payload = make([]byte, *header.Length)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return nil, fmt.Errorf("reading envelope item: %v", err)
+			}
+			reader.ReadByte() // the newline after the payload, if any
+		} else {
+			payload, _ = reader.ReadBytes('\n')
+		}
+		if header.Type != "event" {
+			continue
+		}
+		event, err := decodeEvent(payload)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+}
+
+// decodeEvent decodes the JSON of a single event.
+func decodeEvent(data []byte) (Event, error) {
+	var event Event
+	if err := json.Unmarshal(data, &event.Raw); err != nil {
+		return event, fmt.Errorf("decoding event: %v", err)
+	}
+	var top struct {
+		EventID     string                 `json:"event_id"`
+		Level       string                 `json:"level"`
+		Logger      string                 `json:"logger"`
+		Culprit     string                 `json:"culprit"`
+		Release     string                 `json:"release"`
+		Environment string                 `json:"environment"`
+		ServerName  string                 `json:"server_name"`
+		Platform    string                 `json:"platform"`
+		Extra       map[string]interface{} `json:"extra"`
+		Fingerprint []string               `json:"fingerprint"`
+	}
+	if err := json.Unmarshal(data, &top); err != nil {
+		return event, fmt.Errorf("decoding event: %v", err)
+	}
+	event.EventID, event.Level, event.Logger, event.Culprit = top.EventID, top.Level, top.Logger, top.Culprit
+	event.Release, event.Environment, event.ServerName, event.Platform = top.Release, top.Environment, top.ServerName, top.Platform
+	event.Extra, event.Fingerprint = top.Extra, top.Fingerprint
+
+	if raw, ok := event.Raw["message"]; ok {
+		var message string
+		if json.Unmarshal(raw, &message) != nil {
+			// Newer clients may send the message interface under "message".
+			var entry LogEntry
+			if err := json.Unmarshal(raw, &entry); err != nil {
+				return event, fmt.Errorf("decoding message: %v", err)
+			}
+			event.LogEntry = &entry
+			message = entry.Formatted
+			if message == "" {
+				message = entry.Message
+			}
+		}
+		event.Message = message
+	}
+	if raw, ok := event.interfaceJSON("logentry", "sentry.interfaces.Message"); ok {
+		var entry LogEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return event, fmt.Errorf("decoding logentry: %v", err)
+		}
+		event.LogEntry = &entry
+		if event.Message == "" {
+			event.Message = entry.Formatted
+		}
+	}
+	if raw, ok := event.Raw["tags"]; ok {
+		tags, err := decodeTags(raw)
+		if err != nil {
+			return event, err
+		}
+		event.Tags = tags
+	}
+	if raw, ok := event.interfaceJSON("exception", "sentry.interfaces.Exception"); ok {
+		exceptions, err := decodeExceptions(raw)
+		if err != nil {
+			return event, err
+		}
+		event.Exceptions = exceptions
+	}
+	if raw, ok := event.interfaceJSON("stacktrace", "sentry.interfaces.Stacktrace"); ok {
+		event.Stacktrace = &Stacktrace{}
+		if err := json.Unmarshal(raw, event.Stacktrace); err != nil {
+			return event, fmt.Errorf("decoding stacktrace: %v", err)
+		}
+	}
+	if raw, ok := event.interfaceJSON("request", "sentry.interfaces.Http"); ok {
+		event.Request = &Request{}
+		if err := json.Unmarshal(raw, event.Request); err != nil {
+			return event, fmt.Errorf("decoding request: %v", err)
+		}
+	}
+	return event, nil
+}
+
+// interfaceJSON returns the JSON of the interface sent under any of names.
+func (e Event) interfaceJSON(names ...string) (json.RawMessage, bool) {
+	for _, name := range names {
+		if raw, ok := e.Raw[name]; ok && string(raw) != "null" {
+			return raw, true
+		}
+	}
+	return nil, false
+}
+
+// decodeTags decodes tags sent either as an object, or as a list of key/value pairs.
+func decodeTags(raw json.RawMessage) (map[string]string, error) {
+	tags := map[string]string{}
+	if err := json.Unmarshal(raw, &tags); err == nil {
+		return tags, nil
+	}
+	var pairs [][2]string
+	if err := json.Unmarshal(raw, &pairs); err != nil {
+		return nil, fmt.Errorf("decoding tags: %v", err)
+	}
+	for _, pair := range pairs {
+		tags[pair[0]] = pair[1]
+	}
+	return tags, nil
+}
+
+// decodeExceptions decodes the exception interface, which is sent either as a single exception, a
+// list of them, or an object with the list under "values".
+func decodeExceptions(raw json.RawMessage) ([]Exception, error) {
+	var values struct {
+		Values []Exception `json:"values"`
+	}
+	if err := json.Unmarshal(raw, &values); err == nil && values.Values != nil {
+		return values.Values, nil
+	}
+	var list []Exception
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list, nil
+	}
+	var single Exception
+	if err := json.Unmarshal(raw, &single); err != nil {
+		return nil, fmt.Errorf("decoding exception: %v", err)
+	}
+	return []Exception{single}, nil
+}
@@ -0,0 +1,138 @@
+package sentrytest
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func post(t *testing.T, srv *Server, path, contentType string, body []byte) int {
+	url := strings.Replace(srv.DSN(), "public:secret@", "", 1)
+	url = url[:strings.LastIndex(url, "/")] + path
+	resp, err := http.Post(url, contentType, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestStore(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	event := `{"event_id":"abc","message":"disk full","level":"warning","release":"1.2.3",` +
+		`"tags":[["region","us-east-1"],["host","db-1"]],"extra":{"attempt":2},` +
+		`"logentry":{"message":"disk %s","params":["full"]},` +
+		`"exception":{"value":"no space left","type":"*os.PathError","stacktrace":{"frames":[{"function":"write","lineno":12,"in_app":true}]}},` +
+		`"stacktrace":{"frames":[{"filename":"main.go","function":"main","lineno":3},{"filename":"store.go","function":"save","lineno":40,"in_app":true}]},` +
+		`"request":{"url":"http://example.com/upload","method":"POST","query_string":"a=1","headers":{"X-Request-Id":"r1"}}}`
+	if code := post(t, srv, "/api/1/store/", "application/json", []byte(event)); code != http.StatusOK {
+		t.Fatalf("Expected a 200 response, got %d instead\n", code)
+	}
+
+	events := srv.Events()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d instead\n", len(events))
+	}
+	e := events[0]
+	if e.EventID != "abc" || e.Message != "disk full" || e.Level != "warning" || e.Release != "1.2.3" {
+		t.Errorf("Expected the event's attributes to be decoded, got %+v instead\n", e)
+	}
+	if len(e.Tags) != 2 || e.Tags["region"] != "us-east-1" || e.Tags["host"] != "db-1" {
+		t.Errorf("Expected the tags to be decoded, got %v instead\n", e.Tags)
+	}
+	if e.Extra["attempt"] != float64(2) {
+		t.Errorf("Expected the extra data to be decoded, got %v instead\n", e.Extra)
+	}
+	if e.LogEntry == nil || e.LogEntry.Message != "disk %s" || len(e.LogEntry.Params) != 1 {
+		t.Errorf("Expected the message interface to be decoded, got %+v instead\n", e.LogEntry)
+	}
+	if len(e.Exceptions) != 1 || e.Exceptions[0].Value != "no space left" || e.Exceptions[0].Stacktrace == nil || e.Exceptions[0].Stacktrace.Frames[0].Lineno != 12 {
+		t.Errorf("Expected the exception to be decoded, got %+v instead\n", e.Exceptions)
+	}
+	if e.Stacktrace == nil || len(e.Stacktrace.Frames) != 2 || e.Stacktrace.Frames[1] != (Frame{Filename: "store.go", Function: "save", Lineno: 40, InApp: true}) {
+		t.Errorf("Expected the stacktrace to be decoded, got %+v instead\n", e.Stacktrace)
+	}
+	if e.Request == nil || e.Request.Method != "POST" || e.Request.URL != "http://example.com/upload" || e.Request.Headers["X-Request-Id"] != "r1" {
+		t.Errorf("Expected the request to be decoded, got %+v instead\n", e.Request)
+	}
+	if _, ok := e.Raw["logentry"]; !ok {
+		t.Errorf("Expected the raw event to be kept\n")
+	}
+
+	srv.Reset()
+	if events := srv.Events(); len(events) != 0 {
+		t.Errorf("Expected Reset to discard the events, got %d instead\n", len(events))
+	}
+}
+
+func TestStoreCompressed(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	b64 := base64.NewEncoder(base64.StdEncoding, &buf)
+	deflate := zlib.NewWriter(b64)
+	deflate.Write([]byte(`{"message":"compressed","level":"error","tags":{"region":"eu-west-1"},"sentry.interfaces.Exception":[{"value":"boom"}]}`))
+	deflate.Close()
+	b64.Close()
+	if code := post(t, srv, "/api/1/store/", "application/octet-stream", buf.Bytes()); code != http.StatusOK {
+		t.Fatalf("Expected a 200 response, got %d instead\n", code)
+	}
+
+	events := srv.Events()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d instead\n", len(events))
+	}
+	if e := events[0]; e.Message != "compressed" || e.Tags["region"] != "eu-west-1" || len(e.Exceptions) != 1 || e.Exceptions[0].Value != "boom" {
+		t.Errorf("Expected the compressed event to be decoded, got %+v instead\n", e)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	first := `{"event_id":"one","message":{"formatted":"first"},"level":"error"}`
+	envelope := `{"event_id":"one"}` + "\n" +
+		`{"type":"event","length":` + strconv.Itoa(len(first)) + `}` + "\n" + first + "\n" +
+		`{"type":"attachment"}` + "\n" + `not json` + "\n" +
+		`{"type":"event"}` + "\n" + `{"event_id":"two","message":"second","exception":{"values":[{"value":"inner"},{"value":"outer"}]}}` + "\n"
+	if code := post(t, srv, "/api/1/envelope/", "application/x-sentry-envelope", []byte(envelope)); code != http.StatusOK {
+		t.Fatalf("Expected a 200 response, got %d instead: %v\n", code, srv.Errors())
+	}
+
+	events := srv.Events()
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d instead\n", len(events))
+	}
+	if events[0].Message != "first" || events[1].Message != "second" {
+		t.Errorf("Expected the events' messages to be decoded, got %q and %q instead\n", events[0].Message, events[1].Message)
+	}
+	if len(events[1].Exceptions) != 2 || events[1].Exceptions[1].Value != "outer" {
+		t.Errorf("Expected the chained exceptions to be decoded, got %+v instead\n", events[1].Exceptions)
+	}
+}
+
+func TestBadRequest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	if code := post(t, srv, "/api/1/store/", "application/json", []byte("{")); code != http.StatusBadRequest {
+		t.Errorf("Expected a 400 response, got %d instead\n", code)
+	}
+	if code := post(t, srv, "/api/1/unknown/", "application/json", []byte("{}")); code != http.StatusBadRequest {
+		t.Errorf("Expected a 400 response, got %d instead\n", code)
+	}
+	if errs := srv.Errors(); len(errs) != 2 {
+		t.Errorf("Expected 2 errors, got %v instead\n", errs)
+	}
+	if events := srv.Events(); len(events) != 0 {
+		t.Errorf("Expected no events, got %d instead\n", len(events))
+	}
+}
@@ -0,0 +1,179 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// NewSlogHandler returns an slog.Handler that writes through l, so that packages logging with
+// log/slog end up in the same place, in the same format, and in the same Sentry project as
+// everything else:
+//
+//	slog.SetDefault(slog.New(logging.NewSlogHandler(l)))
+//
+// The Levels of this package have the same values as slog's, so records are written at the Level
+// with the same severity, and l's Level, level overrides, and sampling decide which are written. The
+// attributes of a record become fields, named after the groups they're in, like "request.id".
+// Attributes added with WithAttrs also become Sentry tags. Records at WarnLvl and above are sent to
+// Sentry, like statements logged with Warn and Error.
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+type slogHandler struct {
+	l      Logger
+	prefix string
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if h.l.sink() == nil {
+		return false
+	}
+	// With level overrides, whether a record is enabled depends on its call site, which Enabled isn't
+	// told. Handle sorts it out.
+	return h.l.overrides != nil || h.l.GetLevel().Enabled(Level(level))
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	lvl := Level(r.Level)
+	if !h.l.enabledAt(lvl, r.PC) || !h.l.sampledAt(lvl, r.Message, nil, r.PC) {
+		return nil
+	}
+	l := h.l
+	if r.NumAttrs() > 0 {
+		fields := make([]Field, len(l.fields), len(l.fields)+r.NumAttrs())
+		copy(fields, l.fields)
+		r.Attrs(func(a slog.Attr) bool {
+			fields = appendAttr(fields, h.prefix, a)
+			return true
+		})
+		l.fields = fields
+	}
+	now := r.Time
+	if now.IsZero() {
+		now = l.now()
+	}
+	err := l.write(now, r.PC, r.Message, lvl)
+	if err == errHeld {
+		return nil
+	}
+	if lvl >= WarnLvl && l.reporter() != nil {
+		if depth := callerDepth(r.PC); depth > 0 {
+			l.calldepth = depth - 1
+		}
+		l.toSentry(strings.Replace(r.Message, "%", "%%", -1), nil, lvl)
+	}
+	return err
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []Field
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.prefix, a)
+	}
+	if len(fields) == 0 {
+		return h
+	}
+	tags := make(map[string]string, len(fields))
+	for _, f := range fields {
+		tags[f.Key] = fmt.Sprint(f.Value())
+	}
+	return &slogHandler{l: h.l.AddFields(fields...).AddTags(tags), prefix: h.prefix}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{l: h.l, prefix: h.prefix + name + "."}
+}
+
+// appendAttr appends a to fields, flattening groups into fields named after them.
+func appendAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, member := range v.Group() {
+			fields = appendAttr(fields, prefix, member)
+		}
+		return fields
+	}
+	if a.Key == "" && v.Any() == nil {
+		return fields
+	}
+	key := prefix + a.Key
+	switch v.Kind() {
+	case slog.KindString:
+		return append(fields, String(key, v.String()))
+	case slog.KindInt64:
+		return append(fields, Int64(key, v.Int64()))
+	case slog.KindFloat64:
+		return append(fields, Float64(key, v.Float64()))
+	case slog.KindBool:
+		return append(fields, Bool(key, v.Bool()))
+	case slog.KindDuration:
+		return append(fields, Duration(key, v.Duration()))
+	}
+	return append(fields, Any(key, v.Any()))
+}
+
+// callerDepth returns how many frames above its caller the call site pc is, with 0 identifying the
+// caller itself, or -1 if pc isn't on the stack.
+func callerDepth(pc uintptr) int {
+	var pcs [64]uintptr
+	n := runtime.Callers(2, pcs[:])
+	for i, p := range pcs[:n] {
+		if p == pc {
+			return i
+		}
+	}
+	return -1
+}
+
+// SlogSink returns a Sink that hands entries over to h, for programs whose logs are collected by an
+// slog.Handler. Each entry becomes a record with the entry's time, Level, message, and call site, and
+// its fields as attributes; the Logger's Formatter isn't used. Sentry reporting is unaffected.
+//
+// Don't give SlogSink a Handler returned by NewSlogHandler, unless it's for a Logger writing
+// somewhere else: the entries would go round in circles.
+func SlogSink(h slog.Handler) Sink {
+	return &slogSink{h: h}
+}
+
+type slogSink struct {
+	h slog.Handler
+}
+
+func (s *slogSink) WriteEntry(e *Entry, line []byte) error {
+	ctx := context.Background()
+	lvl := slog.Level(e.Level)
+	if !s.h.Enabled(ctx, lvl) {
+		return nil
+	}
+	r := slog.NewRecord(e.Time, lvl, e.Message, e.PC)
+	for _, f := range e.Fields {
+		r.AddAttrs(fieldAttr(f))
+	}
+	return s.h.Handle(ctx, r)
+}
+
+// fieldAttr returns f as an slog.Attr.
+func fieldAttr(f Field) slog.Attr {
+	switch f.kind {
+	case stringKind:
+		return slog.String(f.Key, f.str)
+	case intKind:
+		return slog.Int64(f.Key, f.num)
+	case floatKind, boolKind, durationKind:
+		return slog.Any(f.Key, f.Value())
+	}
+	return slog.Any(f.Key, f.val)
+}
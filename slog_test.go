@@ -0,0 +1,130 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/getsentry/raven-go"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	reporter := &recordingReporter{}
+	log = log.WithFlags(TimeNone | CallerShort).WithReporter(reporter)
+	logger := slog.New(NewSlogHandler(log)).With("service", "api").WithGroup("req")
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.Info("handled", "id", 7, slog.Group("user", "name", "bob smith"), slog.Duration("took", 0))
+	logger.Debug("not written")
+	logger.Warn("disk 90% full", "free", 1.5)
+
+	expected := []string{
+		fmt.Sprintf(`[INFO] slog_test.go:%d: handled service=api req.id=7 req.user.name="bob smith" req.took=0s`, line+1),
+		fmt.Sprintf(`[WARN] slog_test.go:%d: disk 90%% full service=api req.free=1.5`, line+3),
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got `%s`\n", len(expected), buf.String())
+	}
+	for pos, exp := range expected {
+		if lines[pos] != exp {
+			t.Errorf("Expected line %d to be %q, got %q instead\n", pos, exp, lines[pos])
+		}
+	}
+
+	if len(reporter.packets) != 1 {
+		t.Fatalf("Expected 1 packet, got %d instead\n", len(reporter.packets))
+	}
+	packet := reporter.packets[0]
+	if packet.Message != "disk 90% full" || packet.Level != raven.WARNING {
+		t.Errorf("Expected a WARNING event for `disk 90%% full`, got %s `%s` instead\n", packet.Level, packet.Message)
+	}
+	if packet.Extra["req.free"] != 1.5 {
+		t.Errorf("Expected the record's attributes as extra data, got %v instead\n", packet.Extra)
+	}
+	var stack *raven.Stacktrace
+	for _, i := range packet.Interfaces {
+		if s, ok := i.(*raven.Stacktrace); ok {
+			stack = s
+		}
+	}
+	if stack == nil || len(stack.Frames) == 0 {
+		t.Fatal("Expected the event to have a stacktrace")
+	}
+	if frame := stack.Frames[len(stack.Frames)-1]; frame.Function != "TestSlogHandler" || frame.Lineno != line+3 {
+		t.Errorf("Expected the stacktrace to start at TestSlogHandler line %d, got %s line %d instead\n", line+3, frame.Function, frame.Lineno)
+	}
+}
+
+func TestSlogHandlerTags(t *testing.T) {
+	log, err := New(InfoLvl, &bytes.Buffer{}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	handler := NewSlogHandler(log).WithGroup("req").WithAttrs([]slog.Attr{slog.String("id", "abc"), slog.Int("attempt", 2)})
+	tags := handler.(*slogHandler).l.tags
+	if len(tags) != 2 || tags["req.id"] != "abc" || tags["req.attempt"] != "2" {
+		t.Errorf("Expected attributes added with WithAttrs to become tags, got %v instead\n", tags)
+	}
+}
+
+func TestSlogHandlerOverrides(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log, err = log.WithLevelOverrides("slog_test.go=DEBUG")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	slog.New(NewSlogHandler(log)).Debug("overridden")
+	if !strings.Contains(buf.String(), "[DEBUG]") {
+		t.Errorf("Expected the override for the record's call site to apply, got `%s`\n", buf.String())
+	}
+}
+
+func TestSlogSink(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{AddSource: true, Level: slog.LevelDebug})
+	log, err := New(DebugLvl, nil, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithSink(SlogSink(handler))
+
+	_, _, line, _ := runtime.Caller(0)
+	log.AddFields(Int("attempt", 2), Err(fmt.Errorf("timeout"))).Warnf("retrying %s", "db")
+
+	var record struct {
+		Level   string
+		Msg     string
+		Attempt int
+		Error   string
+		Source  struct {
+			File string
+			Line int
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Unexpected error decoding `%s`: %+v\n", buf.String(), err)
+	}
+	if record.Level != "WARN" || record.Msg != "retrying db" || record.Attempt != 2 || record.Error != "timeout" {
+		t.Errorf("Expected the entry to be handed over with its fields, got `%s`\n", buf.String())
+	}
+	if !strings.HasSuffix(record.Source.File, "slog_test.go") || record.Source.Line != line+1 {
+		t.Errorf("Expected the record's source to be slog_test.go:%d, got %s:%d instead\n", line+1, record.Source.File, record.Source.Line)
+	}
+}
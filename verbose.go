@@ -0,0 +1,121 @@
+package logging
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Verbose logs statements at one of the verbosity levels below DebugLvl, if that verbosity level is
+// enabled. It's returned by Logger.V.
+type Verbose struct {
+	l   Logger
+	lvl Level
+	on  bool
+}
+
+// V returns a Verbose that logs at verbosity level n, which is n steps less severe than DebugLvl.
+// V(0) is DebugLvl itself, and V(4) is TraceLvl. Verbosity levels are enabled like any other Level,
+// so they can be controlled at runtime with a LevelVar (set to DebugLvl-2, or "DEBUG-2", to turn on
+// V(1) and V(2)) and per package with WithLevelOverrides.
+//
+//	l.V(2).Infof("cache lookup for %s took %s", key, time.Since(start))
+//
+// If building the arguments is expensive in its own right, check Enabled first.
+func (l Logger) V(n int) Verbose {
+	lvl := DebugLvl - Level(n)
+	return Verbose{l: l, lvl: lvl, on: l.sink() != nil && l.enabled(lvl)}
+}
+
+// Enabled returns true if statements logged with v will be written.
+func (v Verbose) Enabled() bool {
+	return v.on
+}
+
+// Infof writes a log entry at v's verbosity level, interpolating the format string with the arguments
+// passed. See fmt.Sprintf for information on variable placeholders in the format string.
+func (v Verbose) Infof(format string, msg ...interface{}) {
+	if !v.on || !v.l.sampled(v.lvl, format, nil) {
+		return
+	}
+	v.l.logf(format, v.lvl, msg...)
+}
+
+// Info writes a log entry at v's verbosity level, joining each argument passed with a space.
+func (v Verbose) Info(msg ...interface{}) {
+	if !v.on || !v.l.sampled(v.lvl, "", msg) {
+		return
+	}
+	v.l.log(v.lvl, msg...)
+}
+
+// occasion is the state of a call site that has used InfoEveryN, InfoFirstN, or InfoEvery. Each core
+// keeps its own, so Loggers that don't share an output don't share counts either.
+type occasion struct {
+	count uint64
+	next  int64
+}
+
+func (c *core) occasionAt(pc uintptr) *occasion {
+	if o, ok := c.occasions.Load(pc); ok {
+		return o.(*occasion)
+	}
+	o, _ := c.occasions.LoadOrStore(pc, new(occasion))
+	return o.(*occasion)
+}
+
+// InfoEveryN writes a log entry with the Level of InfoLvl the first time it is called from a
+// particular line of code, and every nth time after that, interpolating the format string with the
+// arguments passed. It's meant for tight loops, where logging every iteration would drown out
+// everything else. Calls are counted per line of code, across every Logger that shares l's output,
+// and only while InfoLvl is enabled.
+func (l Logger) InfoEveryN(n int, format string, msg ...interface{}) {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(InfoLvl) {
+		return
+	}
+	count := atomic.AddUint64(&l.core.occasionAt(callSite(l.calldepth+1)).count, 1)
+	if n > 1 && (count-1)%uint64(n) != 0 {
+		return
+	}
+	l.logf(format, InfoLvl, msg...)
+}
+
+// InfoFirstN writes a log entry with the Level of InfoLvl the first n times it is called from a
+// particular line of code, interpolating the format string with the arguments passed, and then stays
+// quiet. Calls are counted per line of code, across every Logger that shares l's output, and only
+// while InfoLvl is enabled. If n is 0 or less, nothing is ever written.
+func (l Logger) InfoFirstN(n int, format string, msg ...interface{}) {
+	if n <= 0 || l.sink() == nil {
+		return
+	}
+	if !l.enabled(InfoLvl) {
+		return
+	}
+	count := atomic.AddUint64(&l.core.occasionAt(callSite(l.calldepth+1)).count, 1)
+	if count > uint64(n) {
+		return
+	}
+	l.logf(format, InfoLvl, msg...)
+}
+
+// InfoEvery writes a log entry with the Level of InfoLvl at most once per interval from a particular
+// line of code, interpolating the format string with the arguments passed. Calls made before the
+// interval has passed since the last entry was written are discarded. Like InfoEveryN, the interval
+// is kept per line of code, across every Logger that shares l's output.
+func (l Logger) InfoEvery(interval time.Duration, format string, msg ...interface{}) {
+	if l.sink() == nil {
+		return
+	}
+	if !l.enabled(InfoLvl) {
+		return
+	}
+	o := l.core.occasionAt(callSite(l.calldepth + 1))
+	now := time.Now().UnixNano()
+	next := atomic.LoadInt64(&o.next)
+	if now < next || !atomic.CompareAndSwapInt64(&o.next, next, now+int64(interval)) {
+		return
+	}
+	l.logf(format, InfoLvl, msg...)
+}
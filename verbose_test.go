@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestV(t *testing.T) {
+	var buf bytes.Buffer
+	v := NewLevelVar(DebugLvl)
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.WithLevelVar(v)
+	if !log.V(0).Enabled() || log.V(1).Enabled() {
+		t.Errorf("Expected only V(0) to be enabled at DEBUG\n")
+	}
+	log.V(1).Infof("Not written")
+	v.Set(DebugLvl - 2)
+	log.V(2).Infof("Test number %d", 2)
+	log.V(3).Info("Not written")
+	if strings.Contains(buf.String(), "Not written") {
+		t.Errorf("Expected disabled verbosity levels to be excluded, got `%s`\n", buf.String())
+	}
+	if !strings.Contains(buf.String(), "[TRACE+2]") || !strings.HasSuffix(buf.String(), "Test number 2\n") {
+		t.Errorf("Expected a TRACE+2 line, got `%s`\n", buf.String())
+	}
+	if log.V(4).lvl != TraceLvl {
+		t.Errorf("Expected V(4) to be TRACE, got %s instead\n", log.V(4).lvl)
+	}
+}
+
+func TestOccasionally(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for i := 0; i < 10; i++ {
+		log.InfoEveryN(3, "every %d", i)
+		log.InfoFirstN(2, "first %d", i)
+		log.InfoEvery(time.Hour, "hourly %d", i)
+		log.WithLevel(WarnLvl).InfoFirstN(1, "not counted %d", i)
+	}
+	log.InfoFirstN(1, "first at a new call site")
+	log.InfoFirstN(0, "never")
+	log.InfoFirstN(-1, "never")
+	expected := []string{"every 0", "first 0", "hourly 0", "first 1", "every 3", "every 6", "every 9", "first at a new call site"}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got `%s`\n", len(expected), buf.String())
+	}
+	for pos, line := range lines {
+		if !strings.HasSuffix(line, expected[pos]) {
+			t.Errorf("Expected line %d to end with `%s`, got `%s`\n", pos, expected[pos], line)
+		}
+	}
+}
+
+func TestOccasionallyPerLogger(t *testing.T) {
+	var first, second bytes.Buffer
+	for _, buf := range []*bytes.Buffer{&first, &second} {
+		log, err := New(InfoLvl, buf, "", nil)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		for i := 0; i < 3; i++ {
+			log.InfoFirstN(1, "first %d", i)
+		}
+	}
+	if !strings.HasSuffix(first.String(), "first 0\n") || !strings.HasSuffix(second.String(), "first 0\n") {
+		t.Errorf("Expected each Logger to count calls separately, got `%s` and `%s`\n", first.String(), second.String())
+	}
+}
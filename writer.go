@@ -0,0 +1,174 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Writer returns an io.WriteCloser that writes each line written to it as a log entry with the Level
+// of lvl. Writes don't have to line up with lines: partial lines are held on to until the rest of the
+// line arrives, and Close writes whatever is left of the last line if it never got a newline. Lines
+// longer than 64KiB are split into entries of that size, so a writer that never writes a newline
+// can't make the Writer hold on to everything it writes. Lines at WarnLvl and above are sent to
+// Sentry, like statements logged with Warn and Error. Closing the Writer doesn't close l.
+//
+// Entries are attributed to the code that called Write, skipping past the standard library's log
+// package, so lines logged through a *log.Logger writing to the Writer point at the code that called
+// Printf. l's Level, level overrides, and sampling decide which lines are written, as usual.
+func (l Logger) Writer(lvl Level) io.WriteCloser {
+	return &levelWriter{l: l, lvl: lvl}
+}
+
+// StdLogger returns a *log.Logger that writes each line logged with it as a log entry with the Level
+// of lvl, for the parts of the standard library and the third-party packages that only know how to
+// log to one of those:
+//
+//	srv := &http.Server{
+//		Addr:     ":8080",
+//		Handler:  mux,
+//		ErrorLog: l.StdLogger(logging.ErrorLvl),
+//	}
+//
+// The *log.Logger has no prefix or flags of its own; the time and caller are written by l.
+func (l Logger) StdLogger(lvl Level) *log.Logger {
+	return log.New(l.Writer(lvl), "", 0)
+}
+
+// RedirectStdLog sends everything logged with the standard library's log package functions, like
+// log.Printf, to l, as log entries with the Level of lvl. It returns a function that puts the log
+// package's output, prefix, and flags back the way they were:
+//
+//	restore := logging.RedirectStdLog(l, logging.InfoLvl)
+//	defer restore()
+//
+// log.Fatal and log.Panic still exit and panic after writing their message, at whatever Level lvl is.
+func RedirectStdLog(l Logger, lvl Level) func() {
+	out, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+	log.SetOutput(l.Writer(lvl))
+	log.SetPrefix("")
+	log.SetFlags(0)
+	return func() {
+		log.SetOutput(out)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+	}
+}
+
+// maxLineLength is the longest line a Writer holds on to before writing it as an entry anyway.
+const maxLineLength = 64 << 10
+
+type levelWriter struct {
+	l   Logger
+	lvl Level
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	if w.l.sink() == nil {
+		return len(p), nil
+	}
+	w.mu.Lock()
+	w.buf = append(w.buf, p...)
+	var lines []string
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		for i > maxLineLength {
+			n := splitAt(w.buf, maxLineLength)
+			lines = append(lines, string(w.buf[:n]))
+			w.buf, i = w.buf[n:], i-n
+		}
+		lines = append(lines, string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	for len(w.buf) > maxLineLength {
+		n := splitAt(w.buf, maxLineLength)
+		lines = append(lines, string(w.buf[:n]))
+		w.buf = w.buf[n:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = w.buf[:0:0]
+	}
+	w.mu.Unlock()
+	return len(p), w.writeLines(lines)
+}
+
+// Close writes what's left of the last line, if it didn't end with a newline.
+func (w *levelWriter) Close() error {
+	w.mu.Lock()
+	var lines []string
+	if len(w.buf) > 0 {
+		lines = append(lines, string(w.buf))
+		w.buf = nil
+	}
+	w.mu.Unlock()
+	return w.writeLines(lines)
+}
+
+// writeLines writes lines as entries, attributed to the caller of the method calling writeLines.
+func (w *levelWriter) writeLines(lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	skip := stdlogSkip(2)
+	var pc uintptr
+	if w.l.flags&CallerNone == 0 || w.l.overrides != nil || w.l.collapser != nil {
+		pc = callSite(skip)
+	}
+	l := w.l
+	l.calldepth = skip - 1
+	for _, line := range lines {
+		if !l.enabledAt(w.lvl, pc) || !l.sampledAt(w.lvl, line, nil, pc) {
+			continue
+		}
+		err := l.write(l.now(), pc, line, w.lvl)
+		if err == errHeld {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if w.lvl >= WarnLvl && l.reporter() != nil {
+			l.toSentry(strings.Replace(line, "%", "%%", -1), nil, w.lvl)
+		}
+	}
+	return nil
+}
+
+// splitAt returns where to split buf so that the first part is at most n bytes long, backing up so
+// as not to split a UTF-8 encoded character in two.
+func splitAt(buf []byte, n int) int {
+	for i := n; i > n-utf8.UTFMax && i > 0; i-- {
+		if utf8.RuneStart(buf[i]) {
+			return i
+		}
+	}
+	return n
+}
+
+// stdlogSkip returns skip, the number of stack frames to ascend to find the caller of a Write method,
+// with 0 identifying the caller of stdlogSkip as with runtime.Caller, adjusted to skip past the
+// standard library's log package.
+func stdlogSkip(skip int) int {
+	var pcs [32]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for adjusted := skip; ; adjusted++ {
+		frame, more := frames.Next()
+		if packagePath(frame.Function) != "log" {
+			return adjusted
+		}
+		if !more {
+			return skip
+		}
+	}
+}
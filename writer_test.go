@@ -0,0 +1,184 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/getsentry/raven-go"
+)
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	reporter := &recordingReporter{}
+	l = l.WithFlags(TimeNone | CallerShort).WithReporter(reporter)
+	std := l.StdLogger(ErrorLvl)
+
+	_, _, line, _ := runtime.Caller(0)
+	std.Printf("http: TLS handshake error from %s: %s", "10.0.0.1:5555", "EOF")
+
+	expected := fmt.Sprintf("[ERROR] writer_test.go:%d: http: TLS handshake error from 10.0.0.1:5555: EOF\n", line+1)
+	if buf.String() != expected {
+		t.Errorf("Expected `%s`, got `%s` instead\n", expected, buf.String())
+	}
+	if len(reporter.packets) != 1 {
+		t.Fatalf("Expected 1 packet, got %d instead\n", len(reporter.packets))
+	}
+	packet := reporter.packets[0]
+	if packet.Message != "http: TLS handshake error from 10.0.0.1:5555: EOF" || packet.Level != raven.ERROR {
+		t.Errorf("Expected an ERROR event for the line, got %s `%s` instead\n", packet.Level, packet.Message)
+	}
+	var stack *raven.Stacktrace
+	for _, i := range packet.Interfaces {
+		if s, ok := i.(*raven.Stacktrace); ok {
+			stack = s
+		}
+	}
+	if stack == nil || len(stack.Frames) == 0 {
+		t.Fatal("Expected the event to have a stacktrace")
+	}
+	if frame := stack.Frames[len(stack.Frames)-1]; frame.Function != "TestStdLogger" || frame.Lineno != line+1 {
+		t.Errorf("Expected the stacktrace to start at TestStdLogger line %d, got %s line %d instead\n", line+1, frame.Function, frame.Lineno)
+	}
+}
+
+func TestWriterLines(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	w := l.WithFlags(TimeNone | CallerNone).Writer(InfoLvl)
+
+	writes := []string{"first ", "line\nsecond line\nthi", "rd", " line\n", "\n"}
+	for _, s := range writes {
+		n, err := w.Write([]byte(s))
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if n != len(s) {
+			t.Errorf("Expected Write to report %d bytes, got %d instead\n", len(s), n)
+		}
+	}
+
+	expected := "[INFO] first line\n[INFO] second line\n[INFO] third line\n[INFO] \n"
+	if buf.String() != expected {
+		t.Errorf("Expected `%s`, got `%s` instead\n", expected, buf.String())
+	}
+}
+
+func TestWriterClose(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	w := l.WithFlags(TimeNone | CallerShort).Writer(InfoLvl)
+	fmt.Fprint(w, "exit status 1")
+	if buf.Len() != 0 {
+		t.Errorf("Expected the partial line to be held on to, got `%s`\n", buf.String())
+	}
+	_, _, line, _ := runtime.Caller(0)
+	if err := w.Close(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := fmt.Sprintf("[INFO] writer_test.go:%d: exit status 1\n", line+1)
+	if buf.String() != expected {
+		t.Errorf("Expected `%s`, got `%s` instead\n", expected, buf.String())
+	}
+	w.Close()
+	if buf.String() != expected {
+		t.Errorf("Expected closing twice to write nothing more, got `%s`\n", buf.String())
+	}
+}
+
+func TestWriterLongLines(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	w := l.WithFlags(TimeNone | CallerNone).Writer(InfoLvl)
+	long := strings.Repeat("x", maxLineLength-1) + "é" + strings.Repeat("y", 10)
+	fmt.Fprint(w, long)
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Fatalf("Expected the first %d bytes to be written without waiting for a newline, got %d lines\n", maxLineLength, strings.Count(buf.String(), "\n"))
+	}
+	fmt.Fprint(w, strings.Repeat("z", 2*maxLineLength)+"\nend\n")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 lines, got %d instead\n", len(lines))
+	}
+	var total int
+	for pos, line := range lines {
+		msg := strings.TrimPrefix(line, "[INFO] ")
+		if len(msg) > maxLineLength || !utf8.ValidString(msg) {
+			t.Errorf("Expected line %d to be valid UTF-8 no longer than %d bytes, got %d bytes\n", pos, maxLineLength, len(msg))
+		}
+		total += len(msg)
+	}
+	if expected := len(long) + 2*maxLineLength + len("end"); total != expected {
+		t.Errorf("Expected %d bytes in all, got %d instead\n", expected, total)
+	}
+}
+
+func TestWriterLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	l.StdLogger(DebugLvl).Println("not written")
+	if buf.Len() != 0 {
+		t.Errorf("Expected lines below the Logger's Level to be dropped, got `%s`\n", buf.String())
+	}
+
+	l, err = l.WithLevelOverrides("writer_test.go=DEBUG")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	l.StdLogger(DebugLvl).Println("overridden")
+	if !strings.Contains(buf.String(), "[DEBUG]") {
+		t.Errorf("Expected the override for the caller of Println to apply, got `%s`\n", buf.String())
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	out, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+	defer func() {
+		log.SetOutput(out)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+	}()
+	var previous bytes.Buffer
+	log.SetOutput(&previous)
+	log.SetPrefix("app: ")
+	log.SetFlags(log.Lshortfile)
+
+	restore := RedirectStdLog(l.WithFlags(TimeNone|CallerShort), WarnLvl)
+	_, _, line, _ := runtime.Caller(0)
+	log.Printf("deprecated option %q", "-v")
+	restore()
+	log.Print("after")
+
+	expected := fmt.Sprintf("[WARN] writer_test.go:%d: deprecated option \"-v\"\n", line+1)
+	if buf.String() != expected {
+		t.Errorf("Expected `%s`, got `%s` instead\n", expected, buf.String())
+	}
+	if !strings.HasPrefix(previous.String(), "app: writer_test.go:") || !strings.HasSuffix(previous.String(), ": after\n") {
+		t.Errorf("Expected the log package to be restored, got `%s`\n", previous.String())
+	}
+}